@@ -2,34 +2,272 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 	"virtual-file-system/internal/actions"
-
-	"github.com/google/shlex"
+	"virtual-file-system/internal/services"
+	"virtual-file-system/internal/shell"
+	"virtual-file-system/vfs"
 )
 
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
+	dataDir := flag.String("data-dir", "", "persist the file system under this directory instead of keeping it in memory")
+	checkpointInterval := flag.Int("checkpoint-interval", services.DefaultCheckpointInterval, "number of logged operations between two snapshots")
+	maxVersions := flag.Int("max-versions", services.DefaultMaxVersions, "number of revisions retained per file, 0 keeps every revision")
+	trashRetention := flag.Duration("trash-retention", services.DefaultTrashRetention, "how long deleted items are kept in the trash, 0 keeps them until emptied")
+	history := flag.String("history", defaultHistoryPath(), "file keeping the commands typed in the shell, empty keeps them in memory only")
+	output := flag.String("output", string(actions.FormatTable), "format of the results: table, json, csv or tsv")
+	user := flag.String("user", "", "log in as this user before running the command given as arguments, with the password of $VFS_PASSWORD or --password-file")
+	passwordFile := flag.String("password-file", "", "file holding the password of --user on its first line")
+	flag.Parse()
+
+	format, err := actions.ParseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(actions.ExitInvalid)
+	}
+
+	opts := []vfs.Option{
+		vfs.WithCheckpointInterval(*checkpointInterval),
+		vfs.WithMaxVersions(*maxVersions),
+		vfs.WithTrashRetention(*trashRetention),
+	}
+	if *dataDir != "" {
+		opts = append(opts, vfs.WithDataDir(*dataDir))
+	}
+
+	client, err := vfs.New(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(actions.ExitFailure)
+	}
+
+	out := &actions.Output{Format: format}
+	switch {
+	case flag.Arg(0) == "serve":
+		err = serve(client, flag.Args()[1:])
+	case flag.Arg(0) == "run":
+		err = runScript(client, out, flag.Args()[1:])
+	case flag.NArg() > 0:
+		err = runOnce(client, out, flag.Args(), *user, *passwordFile)
+	default:
+		err = run(client, out, *history)
+	}
+
+	if closeErr := client.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(actions.ExitFailure)
+	}
+
+	// The exit code tells the category of the latest failed command, if any.
+	os.Exit(actions.ExitCode(out.Err()))
+}
+
+// serve runs the REST API server until it is interrupted.
+func serve(client *vfs.Client, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address the REST API listens on")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
 
-	for fmt.Print("# "); scanner.Scan(); fmt.Print("# ") {
-		text := scanner.Text()
-		fmt.Println("$cmd:", text)
+	srv := &http.Server{Addr: *addr, Handler: client.Handler()}
 
-		args, err := shlex.Split(text)
+	// The expired items are purged from the trash every hour, as the server may run for longer than their retention.
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+	go func() {
+		for range purge.C {
+			if err := client.PurgeTrash(); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+			}
+		}
+	}()
+
+	// Interrupts shut the server down gracefully, so the store is closed on the way out.
+	done := make(chan error, 1)
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+		<-interrupt
+		done <- srv.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintln(os.Stderr, "listening on", *addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	return <-done
+}
+
+// runOnce runs the command given on the command line, e.g. "vfs create_folder user1 Work".
+// When username is given, the user is logged in first, see readPassword, and the command runs on their behalf.
+func runOnce(client *vfs.Client, out *actions.Output, args []string, username string, passwordFile string) error {
+	f := &actions.Factory{Client: client, Stdin: os.Stdin, Output: out}
+
+	if username != "" {
+		password, err := readPassword(passwordFile)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "error:", err)
-			os.Exit(1)
+			return err
 		}
 
-		f := &actions.Factory{}
-		if act := f.CreateAction(args); !act.Exec(args) {
-			break
+		// A failed login is printed like the failure of a command, so the exit code tells its category.
+		if err := f.Login(username, password); err != nil {
+			out.Error(err)
+			return nil
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+	f.CreateAction(args).Exec(args)
+
+	return nil
+}
+
+// readPassword returns the first line of the password file if given, or else the VFS_PASSWORD environment variable,
+// which keeps the password out of the command line and the shell history.
+func readPassword(path string) (string, error) {
+	if path == "" {
+		return os.Getenv("VFS_PASSWORD"), nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	password := strings.SplitN(string(data), "\n", 2)[0]
+
+	return strings.TrimSuffix(password, "\r"), nil
+}
+
+// run reads the commands from stdin until "exit" or the end of the input.
+// When stdin is a terminal, the commands are typed in the shell, see runShell.
+func run(client *vfs.Client, out *actions.Output, historyPath string) error {
+	// A single buffered reader is shared with the actions,
+	// so "upload_file ... -" can consume the remaining input as file content.
+	reader := bufio.NewReader(os.Stdin)
+	f := &actions.Factory{Client: client, Stdin: reader, Output: out}
+	runner := actions.NewRunner(f)
+
+	if isTerminal(os.Stdin) {
+		return runShell(f, runner, reader, historyPath)
 	}
+
+	_, err := runLines(runner, reader)
+
+	return err
+}
+
+// runShell reads the commands typed in the terminal until "exit" or ctrl-d,
+// with line editing, a history kept in the file at historyPath, and tab completion.
+func runShell(f *actions.Factory, runner *actions.Runner, reader *bufio.Reader, historyPath string) error {
+	history, err := shell.LoadHistory(historyPath, shell.DefaultHistorySize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: the history is not kept:", err)
+		history, _ = shell.LoadHistory("", shell.DefaultHistorySize)
+	}
+
+	editor := shell.NewEditor(reader, os.Stdout, int(os.Stdin.Fd()))
+	editor.History = history
+	editor.Complete = shell.NewCompleter(f).Complete
+
+	for number := 1; ; number++ {
+		// The prompt is left out of the machine-readable formats, so the output holds nothing but results.
+		prompt := ""
+		if f.Output.Format == actions.FormatTable {
+			prompt = "# "
+		}
+
+		text, err := editor.ReadLine(prompt)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := history.Add(text); err != nil {
+			fmt.Fprintln(os.Stderr, "warning: the history is not kept:", err)
+		}
+
+		if !runner.Run(number, text) {
+			return nil
+		}
+	}
+}
+
+// runScript runs the commands of the script file, e.g. "vfs run script.vfs",
+// then writes a summary of the failed lines to stderr.
+func runScript(client *vfs.Client, out *actions.Output, args []string) error {
+	if len(args) != 1 {
+		out.Usage("Missing arguments: run {script}")
+		return nil
+	}
+
+	script, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer script.Close()
+
+	runner := actions.NewRunner(&actions.Factory{Client: client, Stdin: os.Stdin, Output: out})
+
+	lines, err := runLines(runner, bufio.NewReader(script))
+	if err != nil {
+		return err
+	}
+
+	runner.WriteSummary(os.Stderr, lines)
+
+	return nil
+}
+
+// runLines runs the lines read from reader until "exit", the end of the input, or a failed line after "set -e".
+// It returns the number of lines read.
+func runLines(runner *actions.Runner, reader *bufio.Reader) (int, error) {
+	for number := 1; ; number++ {
+		text, err := reader.ReadString('\n')
+		if err != nil && text == "" {
+			if err != io.EOF {
+				return number - 1, err
+			}
+			return number - 1, nil
+		}
+
+		if !runner.Run(number, strings.TrimRight(text, "\r\n")) {
+			return number, nil
+		}
+	}
+}
+
+// defaultHistoryPath returns the history file in the home directory, or none if there is no home directory.
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".vfs_history")
+}
+
+// isTerminal reports whether the file is a terminal rather than a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
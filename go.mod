@@ -1,5 +1,5 @@
 module virtual-file-system
 
-go 1.14
+go 1.16
 
-require github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
+require github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
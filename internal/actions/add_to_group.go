@@ -0,0 +1,23 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type addToGroup struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec adds a user to a group.
+func (act *addToGroup) Exec(args []string) bool {
+	username := args[1]
+	groupName := args[2]
+	member := args[3]
+
+	if err := act.client.AddToGroup(username, groupName, member); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -0,0 +1,355 @@
+package actions
+
+import "strings"
+
+// ArgKind tells what an argument refers to, so the shell can complete it.
+type ArgKind int
+
+const (
+	// ArgText is any text, which is not completed.
+	ArgText ArgKind = iota
+
+	// ArgUser is the name of a registered user.
+	ArgUser
+
+	// ArgFolder is a folder given by its ID or its path.
+	ArgFolder
+
+	// ArgFolderPath is the name of a new folder at the root of the user, or its path under a folder of the user.
+	ArgFolderPath
+
+	// ArgFile is the name of a file in the folder given by the ArgFolder argument before it.
+	ArgFile
+
+	// ArgTrashItem is the ID of an item in the trash of the user.
+	ArgTrashItem
+
+	// ArgCommand is the name of a command.
+	ArgCommand
+)
+
+// Arg is a positional argument of a command.
+type Arg struct {
+	// Name is how the argument is shown in the usage, e.g. "folder_id|path".
+	Name string
+
+	// Kind tells what the argument refers to.
+	Kind ArgKind
+
+	// Flag is the flag the argument may be given by instead, with its value after "=", e.g. "--sort" for "--sort=sort_time".
+	Flag string
+
+	// Choices lists the values the argument may take, any value if empty.
+	// They are matched regardless of case.
+	Choices []string
+
+	// Synonyms are other spellings of the choices, e.g. "time" for "sort_time".
+	Synonyms map[string]string
+
+	// Optional arguments may be left out, only the last arguments of a command should be.
+	Optional bool
+
+	// Default is the value of an optional argument left out while a later one is given, e.g. by its flag.
+	Default string
+}
+
+// usernameArg is the {username} argument of the commands acting on behalf of a user.
+var usernameArg = Arg{Name: "username", Kind: ArgUser, Flag: "--user"}
+
+// Flag is a flag of a command turning an option on, such as "--recursive", which may be given anywhere after the command name.
+type Flag struct {
+	// Name is the flag as given, e.g. "--recursive".
+	Name string
+
+	// Help tells what the flag does.
+	Help string
+}
+
+// Command describes a command of the shell: how it is called, its arguments and flags, and its help.
+// The commands are listed in Commands, which the factory, the help and the completion of the shell are built from.
+type Command struct {
+	// Name is what the command is called, e.g. "create_folder".
+	Name string
+
+	// Aliases are other names the command can be called by, e.g. "mkdir".
+	Aliases []string
+
+	// User is true when the first argument is the {username} the command acts on behalf of,
+	// which is left out while a user is logged in.
+	User bool
+
+	// Args are the positional arguments, following {username} for the commands acting on behalf of a user.
+	Args []Arg
+
+	// Flags are the flags turning an option on, the arguments may be given by their own flags as well.
+	Flags []Flag
+
+	// Help tells what the command does.
+	Help string
+
+	// create returns the action running the command.
+	create func(f *Factory) Action
+}
+
+// Usage returns how the command is called, e.g. "get_folders {username} [sort_name|sort_time] [asc|dsc]".
+func (cmd *Command) Usage() string {
+	parts := []string{cmd.Name}
+	if cmd.User {
+		parts = append(parts, "{username}")
+	}
+
+	for _, arg := range cmd.Args {
+		name := arg.Name
+		if len(arg.Choices) > 0 {
+			name = strings.Join(arg.Choices, "|")
+		}
+
+		switch {
+		case arg.Optional:
+			parts = append(parts, "["+name+"]")
+		case len(arg.Choices) == 1:
+			parts = append(parts, name)
+		default:
+			parts = append(parts, "{"+name+"}")
+		}
+	}
+
+	for _, flag := range cmd.Flags {
+		parts = append(parts, "["+flag.Name+"]")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Specs returns the arguments of the command, led by {username} for the commands acting on behalf of a user
+// if withUser is true, i.e. while nobody is logged in.
+func (cmd *Command) Specs(withUser bool) []Arg {
+	if !cmd.User || !withUser {
+		return cmd.Args
+	}
+
+	return append([]Arg{usernameArg}, cmd.Args...)
+}
+
+// Commands returns the commands of the shell, in the order of the help.
+func Commands() []*Command {
+	return commands
+}
+
+// LookupCommand returns the command with given name or alias, nil if there is none.
+func LookupCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+
+	return nil
+}
+
+// checked runs the action once its arguments fit the command, see bind.
+// The alias the command is called by is replaced by its name.
+type checked struct {
+	cmd     *Command
+	action  Action
+	session *Session
+	out     *Output
+}
+
+// Exec checks the arguments, then runs the action with the arguments in order.
+// The flags given are handed over apart, see flagged.
+func (act *checked) Exec(args []string) bool {
+	cmd := act.cmd
+
+	values, flags, problem := cmd.bind(args[1:], act.session.Username == "")
+	if problem != "" {
+		act.out.Usage(problem + ": " + cmd.Usage())
+		return true
+	}
+
+	if action, ok := act.action.(flagged); ok {
+		action.SetFlags(flags)
+	}
+
+	return act.action.Exec(append([]string{cmd.Name}, values...))
+}
+
+// Binding is how the words given after the command name are assigned to the arguments of the command, see Bind.
+type Binding struct {
+	// Specs are the arguments of the command, see Command.Specs.
+	Specs []Arg
+
+	// Values holds the values given to the arguments, as they are given, and Given tells which ones are.
+	Values []string
+	Given  []bool
+
+	// Flags are the flags turning an option on.
+	Flags []string
+
+	// Next is the index of the first argument not given yet, -1 if every argument is.
+	Next int
+
+	// Extra are the words left once every argument is given.
+	Extra []string
+
+	// Problem is the first malformed flag, which is left out, empty if there is none.
+	Problem string
+}
+
+// Bind assigns the words given after the command name to the arguments of the command:
+// first the ones given by their flag, e.g. "--sort=sort_time", then the others in order.
+// Everything after "--" is taken as is, e.g. a description starting with "--".
+//
+// The words are bound as far as they go, so the ones typed so far can be completed:
+// the values are neither checked against the choices nor defaulted, see bind.
+func (cmd *Command) Bind(args []string, withUser bool) Binding {
+	specs := cmd.Specs(withUser)
+	b := Binding{Specs: specs, Values: make([]string, len(specs)), Given: make([]bool, len(specs)), Next: -1}
+
+	problem := func(message string) {
+		if b.Problem == "" {
+			b.Problem = message
+		}
+	}
+
+	var positional []string
+	for i, arg := range args {
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+		if i := strings.Index(arg, "="); i >= 0 {
+			name, value, hasValue = arg[:i], arg[i+1:], true
+		}
+
+		if cmd.hasFlag(name) {
+			if hasValue {
+				problem("Flag " + name + " takes no value")
+				continue
+			}
+			b.Flags = append(b.Flags, name)
+			continue
+		}
+
+		j := b.Index(name)
+		switch {
+		case j < 0:
+			problem("Unknown flag " + name)
+		case value == "":
+			problem("Flag " + name + " needs a value, e.g. " + name + "=...")
+		case b.Given[j]:
+			problem("Flag " + name + " is given twice")
+		default:
+			b.Values[j], b.Given[j] = value, true
+		}
+	}
+
+	for j := range specs {
+		if b.Given[j] {
+			continue
+		}
+
+		if len(positional) == 0 {
+			if b.Next < 0 {
+				b.Next = j
+			}
+			continue
+		}
+		b.Values[j], b.Given[j] = positional[0], true
+		positional = positional[1:]
+	}
+	if len(positional) > 0 {
+		b.Extra = positional
+	}
+
+	return b
+}
+
+// Index returns the index of the argument given by the flag, e.g. "--sort", -1 if there is none.
+func (b Binding) Index(flag string) int {
+	for i, spec := range b.Specs {
+		if spec.Flag != "" && spec.Flag == flag {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// bind binds the arguments given after the command name, see Bind, and checks them.
+//
+// It returns the values of the arguments up to the last one given, the optional ones left out in between
+// being set to their default, and the flags turning an option on. The problem with the arguments is returned instead,
+// such as a missing or extra argument, an unknown or malformed flag, or a value out of the choices.
+func (cmd *Command) bind(args []string, withUser bool) (values []string, flags []string, problem string) {
+	b := cmd.Bind(args, withUser)
+	if b.Problem != "" {
+		return nil, nil, b.Problem
+	}
+
+	if len(b.Extra) > 0 {
+		return nil, nil, "Too many arguments"
+	}
+
+	last := -1
+	for j, spec := range b.Specs {
+		switch {
+		case !b.Given[j] && !spec.Optional:
+			return nil, nil, "Missing arguments"
+		case !b.Given[j]:
+			b.Values[j] = spec.Default
+		case len(spec.Choices) > 0:
+			choice := spec.choice(b.Values[j])
+			if choice == "" {
+				return nil, nil, "Invalid argument " + b.Values[j]
+			}
+			b.Values[j] = choice
+		}
+
+		if b.Given[j] {
+			last = j
+		}
+	}
+
+	return b.Values[:last+1], b.Flags, ""
+}
+
+func (cmd *Command) hasFlag(name string) bool {
+	for _, flag := range cmd.Flags {
+		if flag.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// choice returns the choice matching the value or one of its synonyms regardless of case, empty if none does.
+func (arg Arg) choice(value string) string {
+	for _, choice := range arg.Choices {
+		if strings.EqualFold(choice, value) {
+			return choice
+		}
+	}
+
+	for synonym, choice := range arg.Synonyms {
+		if strings.EqualFold(synonym, value) {
+			return choice
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,256 @@
+package actions
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"virtual-file-system/vfs"
+)
+
+func TestFactory_CreateAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands [][]string
+		want     string
+	}{
+		{
+			name:     "01. it should print the usage of the command when arguments are missing.",
+			commands: [][]string{{"rename_folder", "luke", "1001"}},
+			want:     "Error - Missing arguments: rename_folder {username} {folder_id|path} {new_folder_name}\n",
+		},
+		{
+			name:     "02. it should refuse extra arguments.",
+			commands: [][]string{{"register", "luke", "secret", "extra"}},
+			want:     "Error - Too many arguments: register {username} [password]\n",
+		},
+		{
+			name:     "03. it should refuse unknown flags.",
+			commands: [][]string{{"delete_folder", "luke", "/Work", "--force"}},
+			want:     "Error - Unknown flag --force: delete_folder {username} {folder_id|path} [--recursive]\n",
+		},
+		{
+			name:     "04. it should refuse values out of the choices.",
+			commands: [][]string{{"get_folders", "luke", "sort_size"}},
+			want:     "Error - Invalid argument sort_size: get_folders {username} [sort_name|sort_time] [asc|dsc]\n",
+		},
+		{
+			name:     "05. it should match the choices regardless of case.",
+			commands: [][]string{{"set", "Output", "JSON"}},
+			want:     `{"status":"success"}` + "\n",
+		},
+		{
+			name:     "06. it should run the commands called by an alias.",
+			commands: [][]string{{"register", "luke"}, {"mkdir", "luke", "Work"}, {"rmdir", "luke", "Work", "--recursive"}},
+			want:     "Success\n1001\nSuccess\n",
+		},
+		{
+			name:     "07. it should leave out the {username} argument while a user is logged in.",
+			commands: [][]string{{"register", "luke"}, {"login", "luke"}, {"list_trash", "luke"}},
+			want:     "Success\nSuccess\nError - Too many arguments: list_trash {username}\n",
+		},
+		{
+			name:     "08. it should describe the command with its aliases and flags.",
+			commands: [][]string{{"help", "rmdir"}},
+			want: "delete_folder {username} {folder_id|path} [--recursive]\n" +
+				"    Moves an empty folder to the trash.\n" +
+				"    aliases: rmdir\n" +
+				"    --user=username\n" +
+				"    --folder=folder_id|path\n" +
+				"    --recursive: deletes the subfolders and the files as well\n",
+		},
+		{
+			name:     "09. it should return error for the help of an unknown command.",
+			commands: [][]string{{"help", "format"}},
+			want:     "Error -  unknown command\n",
+		},
+		{
+			name:     "10. it should take a value spelled like a flag after -- for a value.",
+			commands: [][]string{{"register", "luke"}, {"create_folder", "luke", "--", "--recursive"}, {"delete_folder", "luke", "--", "--recursive"}},
+			want:     "Success\n1001\nSuccess\n",
+		},
+		{
+			name:     "11. it should take a value spelled like a flag given by its flag for a value.",
+			commands: [][]string{{"register", "luke"}, {"create_folder", "luke", "--", "--recursive"}, {"delete_folder", "luke", "--folder=--recursive"}},
+			want:     "Success\n1001\nSuccess\n",
+		},
+		{
+			name: "12. it should not take a description spelled like a flag for the flag.",
+			commands: [][]string{
+				{"register", "luke"},
+				{"create_folder", "luke", "Work"},
+				{"upload_file", "luke", "/Work", "todo.txt", "--", "--new-version"},
+				{"upload_file", "luke", "/Work", "todo.txt", "--", "--new-version"},
+			},
+			want: "Success\n1001\nSuccess\nError -  file already exists\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := vfs.New()
+			defer client.Close()
+
+			var buf bytes.Buffer
+			f := &Factory{Client: client, Output: &Output{W: &buf}}
+			for _, args := range tt.commands {
+				f.CreateAction(args).Exec(args)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Factory.CreateAction() printed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	names := make(map[string]bool)
+	for _, cmd := range Commands() {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if names[name] {
+				t.Errorf("%s is the name of several commands", name)
+			}
+			names[name] = true
+		}
+
+		optional := false
+		for _, arg := range cmd.Args {
+			if optional && !arg.Optional {
+				t.Errorf("%s has a required argument %s after an optional one", cmd.Name, arg.Name)
+			}
+			optional = optional || arg.Optional
+		}
+
+		if cmd.Help == "" || cmd.create == nil {
+			t.Errorf("%s should have a help and an action", cmd.Name)
+		}
+	}
+}
+
+func TestCommand_Bind(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		args      []string
+		wantNext  int
+		wantExtra []string
+	}{
+		{
+			name:     "01. it should tell the first argument not given yet.",
+			command:  "get_files",
+			args:     []string{"luke", "--order=dsc"},
+			wantNext: 1,
+		},
+		{
+			name:     "02. it should bind the words past a malformed flag.",
+			command:  "get_files",
+			args:     []string{"luke", "--sort", "/Work"},
+			wantNext: 2,
+		},
+		{
+			name:      "03. it should keep the words left once every argument is given.",
+			command:   "create_folder",
+			args:      []string{"luke", "Work", "desc", "extra"},
+			wantNext:  -1,
+			wantExtra: []string{"extra"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := LookupCommand(tt.command).Bind(tt.args, true)
+			if b.Next != tt.wantNext {
+				t.Errorf("Command.Bind() next = %d, want %d", b.Next, tt.wantNext)
+			}
+			if !reflect.DeepEqual(b.Extra, tt.wantExtra) {
+				t.Errorf("Command.Bind() extra = %q, want %q", b.Extra, tt.wantExtra)
+			}
+		})
+	}
+}
+
+func TestCommand_bind(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        []string
+		wantValues  []string
+		wantFlags   []string
+		wantProblem string
+	}{
+		{
+			name:       "01. it should take the arguments given by their flag, spelled as synonyms.",
+			command:    "get_files",
+			args:       []string{"--order=desc", "luke", "--sort=time", "/Work"},
+			wantValues: []string{"luke", "/Work", "sort_time", "dsc"},
+		},
+		{
+			name:       "02. it should set the optional arguments left out before a given one to their default.",
+			command:    "get_folders",
+			args:       []string{"luke", "--order=dsc"},
+			wantValues: []string{"luke", "sort_name", "dsc"},
+		},
+		{
+			name:       "03. it should leave the optional arguments out after the last one given.",
+			command:    "upload_file",
+			args:       []string{"--new-version", "luke", "/Work", "--file=todo.txt", "--desc=The --new-version of the list"},
+			wantValues: []string{"luke", "/Work", "todo.txt", "The --new-version of the list"},
+			wantFlags:  []string{"--new-version"},
+		},
+		{
+			name:       "04. it should take the arguments after -- as they are.",
+			command:    "create_folder",
+			args:       []string{"luke", "--", "Work", "--recursive"},
+			wantValues: []string{"luke", "Work", "--recursive"},
+		},
+		{
+			name:        "05. it should refuse a flag without its value.",
+			command:     "get_files",
+			args:        []string{"luke", "/Work", "--sort"},
+			wantProblem: "Flag --sort needs a value, e.g. --sort=...",
+		},
+		{
+			name:        "06. it should refuse a value given to a flag turning an option on.",
+			command:     "delete_folder",
+			args:        []string{"luke", "/Work", "--recursive=yes"},
+			wantProblem: "Flag --recursive takes no value",
+		},
+		{
+			name:        "07. it should refuse a flag given twice.",
+			command:     "create_folder",
+			args:        []string{"luke", "Work", "--desc=a", "--desc=b"},
+			wantProblem: "Flag --desc is given twice",
+		},
+		{
+			name:        "08. it should refuse an argument given both by its flag and in order when nothing else takes it.",
+			command:     "rename_folder",
+			args:        []string{"luke", "--folder=/Work", "/Work", "Temp"},
+			wantProblem: "Too many arguments",
+		},
+		{
+			name:        "09. it should refuse unknown flags.",
+			command:     "get_files",
+			args:        []string{"luke", "/Work", "--limit=10"},
+			wantProblem: "Unknown flag --limit",
+		},
+		{
+			name:        "10. it should refuse values out of the choices given by their flag.",
+			command:     "get_files",
+			args:        []string{"luke", "/Work", "--sort=size"},
+			wantProblem: "Invalid argument size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, flags, problem := LookupCommand(tt.command).bind(tt.args, true)
+			if problem != tt.wantProblem {
+				t.Errorf("Command.bind() problem = %q, want %q", problem, tt.wantProblem)
+				return
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Command.bind() values = %q, want %q", values, tt.wantValues)
+			}
+			if !reflect.DeepEqual(flags, tt.wantFlags) {
+				t.Errorf("Command.bind() flags = %q, want %q", flags, tt.wantFlags)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package actions
+
+var (
+	folderArg      = Arg{Name: "folder_id|path", Kind: ArgFolder, Flag: "--folder"}
+	fileArg        = Arg{Name: "file_name", Kind: ArgFile, Flag: "--file"}
+	descriptionArg = Arg{Name: "description", Flag: "--desc", Optional: true}
+	granteeArg     = Arg{Name: "grantee|@group", Kind: ArgUser, Flag: "--grantee"}
+	groupArg       = Arg{Name: "group_name", Flag: "--group"}
+	memberArg      = Arg{Name: "member", Kind: ArgUser, Flag: "--member"}
+
+	// The sort and the order, e.g. "--sort=time --order=desc".
+	sortSynonyms = map[string]string{"name": "sort_name", "time": "sort_time", "extension": "sort_extension", "ext": "sort_extension"}
+	orderArg     = Arg{
+		Name:     "order",
+		Flag:     "--order",
+		Choices:  []string{"asc", "dsc"},
+		Synonyms: map[string]string{"desc": "dsc"},
+		Optional: true,
+		Default:  "asc",
+	}
+)
+
+// commands are the commands of the shell, in the order of the help.
+var commands = []*Command{
+	{
+		Name:   "help",
+		Args:   []Arg{{Name: "command", Kind: ArgCommand, Flag: "--command", Optional: true}},
+		Help:   "Lists the commands, or describes the given one.",
+		create: func(f *Factory) Action { return &help{f.output()} },
+	},
+	{
+		Name:   "register",
+		Args:   []Arg{{Name: "username", Flag: "--user"}, {Name: "password", Flag: "--password", Optional: true}},
+		Help:   "Registers a user, who has to log in with the password unless it is empty.",
+		create: func(f *Factory) Action { return &register{f.Client, f.output()} },
+	},
+	{
+		Name:   "login",
+		Args:   []Arg{{Name: "username", Kind: ArgUser, Flag: "--user"}, {Name: "password", Flag: "--password", Optional: true}},
+		Help:   "Logs the user in, so the following commands act on behalf of that user and leave out {username}.",
+		create: func(f *Factory) Action { return &login{f.Client, &f.session, f.output()} },
+	},
+	{
+		Name:   "logout",
+		Help:   "Logs the user out.",
+		create: func(f *Factory) Action { return &logout{&f.session, f.output()} },
+	},
+	{
+		Name: "set",
+		Args: []Arg{
+			{Name: "setting", Choices: []string{"output"}},
+			{Name: "format", Flag: "--format", Choices: formatNames()},
+		},
+		Help:   "Changes the format the results are printed in.",
+		create: func(f *Factory) Action { return &set{f.output()} },
+	},
+	{
+		Name:    "exit",
+		Aliases: []string{"quit"},
+		Help:    "Leaves the shell.",
+		create:  func(f *Factory) Action { return &exit{f.output()} },
+	},
+	{
+		Name:    "create_folder",
+		Aliases: []string{"mkdir"},
+		User:    true,
+		Args: []Arg{
+			{Name: "folder_name|path", Kind: ArgFolderPath, Flag: "--path"},
+			descriptionArg,
+		},
+		Help:   "Creates a folder at the root of the user, or under the folder of the path, and prints its ID.",
+		create: func(f *Factory) Action { return &createFolder{f.Client, f.output()} },
+	},
+	{
+		Name: "get_folders",
+		User: true,
+		Args: []Arg{
+			{Name: "sort", Flag: "--sort", Choices: []string{"sort_name", "sort_time"}, Synonyms: sortSynonyms, Optional: true, Default: "sort_name"},
+			orderArg,
+		},
+		Help:   "Lists the folders the user can read, flagging the ones shared by other users.",
+		create: func(f *Factory) Action { return &getFolders{f.Client, f.output()} },
+	},
+	{
+		Name:   "rename_folder",
+		User:   true,
+		Args:   []Arg{folderArg, {Name: "new_folder_name", Flag: "--name"}},
+		Help:   "Renames a folder.",
+		create: func(f *Factory) Action { return &renameFolder{f.Client, f.output()} },
+	},
+	{
+		Name:    "delete_folder",
+		Aliases: []string{"rmdir"},
+		User:    true,
+		Args:    []Arg{folderArg},
+		Flags:   []Flag{{Name: "--recursive", Help: "deletes the subfolders and the files as well"}},
+		Help:    "Moves an empty folder to the trash.",
+		create:  func(f *Factory) Action { return &deleteFolder{client: f.Client, out: f.output()} },
+	},
+	{
+		Name: "upload_file",
+		User: true,
+		Args: []Arg{
+			folderArg,
+			fileArg,
+			descriptionArg,
+			{Name: "host_path|-", Flag: "--source", Optional: true},
+		},
+		Flags:  []Flag{{Name: "--new-version", Help: "adds a version to the existing file"}},
+		Help:   "Uploads a file from the host, or from stdin with \"-\", or creates it empty without a source.",
+		create: func(f *Factory) Action { return &uploadFile{client: f.Client, stdin: f.Stdin, out: f.output()} },
+	},
+	{
+		Name:   "list_versions",
+		User:   true,
+		Args:   []Arg{folderArg, fileArg},
+		Help:   "Lists the versions of a file.",
+		create: func(f *Factory) Action { return &listVersions{f.Client, f.output()} },
+	},
+	{
+		Name:   "restore_version",
+		User:   true,
+		Args:   []Arg{folderArg, fileArg, {Name: "version", Flag: "--version"}},
+		Help:   "Makes a previous version of a file the latest.",
+		create: func(f *Factory) Action { return &restoreVersion{f.Client, f.output()} },
+	},
+	{
+		Name:   "download_file",
+		User:   true,
+		Args:   []Arg{folderArg, fileArg, {Name: "host_path|-", Flag: "--target", Optional: true}},
+		Help:   "Saves a file to the host, or prints it without a path or with \"-\".",
+		create: func(f *Factory) Action { return &downloadFile{f.Client, f.output()} },
+	},
+	{
+		Name:    "delete_file",
+		Aliases: []string{"rm"},
+		User:    true,
+		Args:    []Arg{folderArg, fileArg},
+		Help:    "Moves a file to the trash.",
+		create:  func(f *Factory) Action { return &deleteFile{f.Client, f.output()} },
+	},
+	{
+		Name:    "get_files",
+		Aliases: []string{"ls"},
+		User:    true,
+		Args: []Arg{
+			folderArg,
+			{Name: "sort", Flag: "--sort", Choices: []string{"sort_name", "sort_time", "sort_extension"}, Synonyms: sortSynonyms, Optional: true, Default: "sort_name"},
+			orderArg,
+		},
+		Help:   "Lists the files of a folder.",
+		create: func(f *Factory) Action { return &getFiles{f.Client, f.output()} },
+	},
+	{
+		Name: "share_folder",
+		User: true,
+		Args: []Arg{
+			folderArg,
+			granteeArg,
+			{Name: "access", Flag: "--access", Choices: []string{"ro", "rw"}},
+		},
+		Help:   "Shares a folder the user owns with another user or a group, read-only or read-write.",
+		create: func(f *Factory) Action { return &shareFolder{f.Client, f.output()} },
+	},
+	{
+		Name:   "unshare_folder",
+		User:   true,
+		Args:   []Arg{folderArg, granteeArg},
+		Help:   "Stops sharing a folder with a user or a group.",
+		create: func(f *Factory) Action { return &unshareFolder{f.Client, f.output()} },
+	},
+	{
+		Name:   "create_group",
+		User:   true,
+		Args:   []Arg{groupArg},
+		Help:   "Creates a group, which only the user can change the members of.",
+		create: func(f *Factory) Action { return &createGroup{f.Client, f.output()} },
+	},
+	{
+		Name:   "add_to_group",
+		User:   true,
+		Args:   []Arg{groupArg, memberArg},
+		Help:   "Adds a member to a group.",
+		create: func(f *Factory) Action { return &addToGroup{f.Client, f.output()} },
+	},
+	{
+		Name:   "remove_from_group",
+		User:   true,
+		Args:   []Arg{groupArg, memberArg},
+		Help:   "Removes a member from a group.",
+		create: func(f *Factory) Action { return &removeFromGroup{f.Client, f.output()} },
+	},
+	{
+		Name:   "list_trash",
+		User:   true,
+		Help:   "Lists the folders and files the user deleted.",
+		create: func(f *Factory) Action { return &listTrash{f.Client, f.output()} },
+	},
+	{
+		Name:   "restore",
+		User:   true,
+		Args:   []Arg{{Name: "item_id", Kind: ArgTrashItem, Flag: "--item"}},
+		Help:   "Puts an item of the trash back where it was.",
+		create: func(f *Factory) Action { return &restore{f.Client, f.output()} },
+	},
+	{
+		Name:   "empty_trash",
+		User:   true,
+		Help:   "Deletes the items of the trash for good.",
+		create: func(f *Factory) Action { return &emptyTrash{f.Client, f.output()} },
+	},
+}
+
+// formatNames returns the names of the output formats.
+func formatNames() []string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+
+	return names
+}
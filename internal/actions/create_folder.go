@@ -1,21 +1,14 @@
 package actions
 
-import (
-	"fmt"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type createFolder struct {
-	folderService services.FolderService
+	client *vfs.Client
+	out    *Output
 }
 
 // Exec creates a folder.
 func (act *createFolder) Exec(args []string) bool {
-	if len(args) < 3 {
-		fmt.Println("Error - Missing arguments: create_folder {username} {folder_name} {description}")
-		return true
-	}
-
 	username := args[1]
 	folderName := args[2]
 
@@ -24,11 +17,11 @@ func (act *createFolder) Exec(args []string) bool {
 		description = args[3]
 	}
 
-	f, err := act.folderService.Create(folderName, username, description)
+	f, err := act.client.CreateFolder(username, folderName, description)
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		fmt.Println(f.ID)
+		act.out.Object([]string{"id"}, []interface{}{f.ID})
 	}
 
 	return true
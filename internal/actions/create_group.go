@@ -0,0 +1,22 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type createGroup struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec creates a group.
+func (act *createGroup) Exec(args []string) bool {
+	username := args[1]
+	groupName := args[2]
+
+	if _, err := act.client.CreateGroup(username, groupName); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -1,35 +1,24 @@
 package actions
 
-import (
-	"fmt"
-	"strconv"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type deleteFolder struct {
-	folderService services.FolderService
+	flagSet
+	client *vfs.Client
+	out    *Output
 }
 
 // Exec deletes a folder.
 func (act *deleteFolder) Exec(args []string) bool {
-	if len(args) < 3 {
-		fmt.Println("Error - Missing arguments: delete_folder {username} {folder_id}")
-		return true
-	}
-
+	recursive := act.has("--recursive")
 	username := args[1]
-	folderID, err := strconv.Atoi(args[2])
-
-	if err != nil {
-		fmt.Println("Error - {folder_id} should be integer")
-		return true
-	}
+	folderRef := args[2]
 
-	err = act.folderService.Delete(folderID, username)
+	err := act.client.DeleteFolder(username, folderRef, recursive)
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		fmt.Println("Success")
+		act.out.Success()
 	}
 
 	return true
@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"io"
+	"os"
+	"virtual-file-system/vfs"
+)
+
+type downloadFile struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec writes the content of a file to the host path, or to stdout if no path is given.
+func (act *downloadFile) Exec(args []string) bool {
+	username := args[1]
+	fileName := args[3]
+	folderRef := args[2]
+
+	content, err := act.client.Open(username, folderRef, fileName)
+	if err != nil {
+		act.out.Error(err)
+		return true
+	}
+	defer content.Close()
+
+	if len(args) < 5 || args[4] == "-" {
+		if err := act.out.Content(content); err != nil {
+			act.out.Error(err)
+		}
+		return true
+	}
+
+	f, err := os.Create(args[4])
+	if err != nil {
+		act.out.Error(err)
+		return true
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, content); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -0,0 +1,19 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type emptyTrash struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec permanently removes the deleted items of a user.
+func (act *emptyTrash) Exec(args []string) bool {
+	if err := act.client.EmptyTrash(args[1]); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
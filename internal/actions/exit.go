@@ -2,10 +2,15 @@ package actions
 
 import "fmt"
 
-type exit struct{}
+type exit struct {
+	out *Output
+}
 
 // Exec returns false to indicate the program should be terminated
 func (act *exit) Exec(args []string) bool {
-	fmt.Println("bye")
+	// Only people are greeted, scripts reading the other formats expect results only.
+	if act.out.format() == FormatTable {
+		fmt.Fprintln(act.out.writer(), "bye")
+	}
 	return false
 }
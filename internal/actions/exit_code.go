@@ -0,0 +1,49 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+// The exit codes of the command line, telling the category of the failure to shell pipelines and CI jobs.
+const (
+	// ExitOK means every command succeeded.
+	ExitOK = 0
+
+	// ExitFailure means a command failed for a reason falling in no other category, such as an I/O failure.
+	ExitFailure = 1
+
+	// ExitInvalid means a command was unknown or given wrong arguments.
+	ExitInvalid = 2
+
+	// ExitNotFound means a user, folder, file or deleted item does not exist.
+	ExitNotFound = 3
+
+	// ExitAlreadyExists means a name is already taken.
+	ExitAlreadyExists = 4
+
+	// ExitPermissionDenied means the user is not allowed to perform the operation.
+	ExitPermissionDenied = 5
+
+	// ExitUnauthenticated means the credentials are wrong or missing.
+	ExitUnauthenticated = 6
+
+	// ExitConflict means the operation does not fit the current state, such as deleting a non-empty folder.
+	ExitConflict = 7
+)
+
+var exitCodes = map[vfs.Kind]int{
+	vfs.Other:            ExitFailure,
+	vfs.Invalid:          ExitInvalid,
+	vfs.NotFound:         ExitNotFound,
+	vfs.AlreadyExists:    ExitAlreadyExists,
+	vfs.PermissionDenied: ExitPermissionDenied,
+	vfs.Unauthenticated:  ExitUnauthenticated,
+	vfs.Conflict:         ExitConflict,
+}
+
+// ExitCode returns the exit code matching the category of err, ExitOK if err is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	return exitCodes[vfs.KindOf(err)]
+}
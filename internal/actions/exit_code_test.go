@@ -0,0 +1,81 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"virtual-file-system/vfs"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "01. it should return ExitOK without error.",
+			want: ExitOK,
+		},
+		{
+			name: "02. it should return the code of the kind of the error.",
+			err:  &vfs.Error{Kind: vfs.NotFound, Err: errors.New("folder does not exist")},
+			want: ExitNotFound,
+		},
+		{
+			name: "03. it should return the code of the kind of a wrapped error.",
+			err:  fmt.Errorf("restore: %w", &vfs.Error{Kind: vfs.Conflict, Err: errors.New("parent no longer exists")}),
+			want: ExitConflict,
+		},
+		{
+			name: "04. it should return ExitFailure for other errors.",
+			err:  errors.New("disk is full"),
+			want: ExitFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutput_Err(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func(out *Output)
+		want  int
+	}{
+		{
+			name:  "01. it should not record a failure on success.",
+			print: func(out *Output) { out.Success() },
+			want:  ExitOK,
+		},
+		{
+			name:  "02. it should record usage errors as invalid.",
+			print: func(out *Output) { out.Usage("Missing arguments: list_trash {username}") },
+			want:  ExitInvalid,
+		},
+		{
+			name: "03. it should keep the latest failure.",
+			print: func(out *Output) {
+				out.Usage("Missing arguments: list_trash {username}")
+				out.Error(&vfs.Error{Kind: vfs.PermissionDenied, Err: errors.New("permission denied")})
+				out.Success()
+			},
+			want: ExitPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &Output{W: ioutil.Discard}
+			tt.print(out)
+			if got := ExitCode(out.Err()); got != tt.want {
+				t.Errorf("ExitCode(Output.Err()) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
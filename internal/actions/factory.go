@@ -1,38 +1,66 @@
 package actions
 
-import "virtual-file-system/internal/services"
+import (
+	"io"
+	"virtual-file-system/vfs"
+)
 
 // Factory decides which action to execute
-type Factory struct{}
+type Factory struct {
+	// Client is the file system the actions operate on.
+	Client *vfs.Client
 
-// CreateAction decides which action to execute
+	// Stdin is where actions read content from when "-" is given as the source.
+	Stdin io.Reader
+
+	// Output prints the results of the actions, tables to the standard output if nil.
+	// Its format can be changed between actions, e.g. by "set output json".
+	Output *Output
+
+	session Session
+}
+
+// SessionUser returns the user logged in to the shell, empty if nobody is logged in.
+func (f *Factory) SessionUser() string {
+	return f.session.Username
+}
+
+// Login authenticates the user and logs them in, so the following actions run on behalf of that user
+// as after the login command, without printing anything.
+func (f *Factory) Login(username string, password string) error {
+	if err := f.Client.Authenticate(username, password); err != nil {
+		return err
+	}
+
+	f.session.Username = username
+
+	return nil
+}
+
+// CreateAction decides which action to execute, given by its name or alias in Commands.
 func (f *Factory) CreateAction(args []string) Action {
 	if len(args) == 0 {
-		return &unknown{}
+		return &unknown{f.output()}
 	}
 
-	serviceFactory := services.GetFactory()
-
-	switch args[0] {
-	case "register":
-		return &register{serviceFactory.GetUserService()}
-	case "create_folder":
-		return &createFolder{serviceFactory.GetFolderService()}
-	case "get_folders":
-		return &getFolders{serviceFactory.GetFolderService()}
-	case "rename_folder":
-		return &renameFolder{serviceFactory.GetFolderService()}
-	case "delete_folder":
-		return &deleteFolder{serviceFactory.GetFolderService()}
-	case "upload_file":
-		return &uploadFile{serviceFactory.GetFileService()}
-	case "delete_file":
-		return &deleteFile{serviceFactory.GetFileService()}
-	case "get_files":
-		return &getFiles{serviceFactory.GetFileService()}
-	case "exit":
-		return &exit{}
-	default:
-		return &unknown{}
+	cmd := LookupCommand(args[0])
+	if cmd == nil {
+		return &unknown{f.output()}
 	}
+
+	act := cmd.create(f)
+	if cmd.User {
+		act = &sessionUser{act, &f.session, f.Client, f.output()}
+	}
+
+	return &checked{cmd, act, &f.session, f.output()}
+}
+
+// output returns the output shared by the actions, creating it if needed.
+func (f *Factory) output() *Output {
+	if f.Output == nil {
+		f.Output = &Output{}
+	}
+
+	return f.Output
 }
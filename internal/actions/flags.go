@@ -0,0 +1,31 @@
+package actions
+
+// flagged is an action taking the flags turning an option on, e.g. "--recursive", apart from its arguments,
+// so an argument spelled like a flag after "--" is never taken for one.
+type flagged interface {
+	Action
+
+	// SetFlags gives the flags of the next run.
+	SetFlags(flags []string)
+}
+
+// flagSet holds the flags given to an action, see flagged.
+type flagSet struct {
+	flags []string
+}
+
+// SetFlags gives the flags of the next run.
+func (s *flagSet) SetFlags(flags []string) {
+	s.flags = flags
+}
+
+// has reports whether the flag was given.
+func (s *flagSet) has(flag string) bool {
+	for _, f := range s.flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
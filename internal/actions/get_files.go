@@ -1,53 +1,51 @@
 package actions
 
-import (
-	"fmt"
-	"strconv"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type getFiles struct {
-	fileService services.FileService
+	client *vfs.Client
+	out    *Output
 }
 
 // Exec get files
 func (act *getFiles) Exec(args []string) bool {
-	if len(args) < 3 {
-		fmt.Println("Error - Missing arguments: get_files {username} {folder_id} {sort_name|sort_time|sort_extension} {asc|dsc}")
-		return true
-	}
 	username := args[1]
-	folderID, err := strconv.Atoi(args[2])
-	if err != nil {
-		fmt.Println("Error - {folder_id} should be integer")
-		return true
-	}
+	folderRef := args[2]
 
+	// The files are sorted in ascending order unless told otherwise.
 	var sortBy, ascOrDsc string
-	if len(args) == 5 {
-		sortBy = args[3]
+	if len(args) >= 4 {
+		sortBy, ascOrDsc = args[3], "asc"
+	}
+	if len(args) >= 5 {
 		ascOrDsc = args[4]
 	}
 
-	files, err := act.fileService.GetAll(username, folderID, sortBy, ascOrDsc)
+	files, err := act.client.Files(username, folderRef, sortBy, ascOrDsc)
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		for _, f := range files {
-			fmt.Print(f.Name)
-			fmt.Print("|")
-			fmt.Print(f.Ext)
-			fmt.Print("|")
-			fmt.Print(f.Desc)
-			fmt.Print("|")
-
-			// https://stackoverflow.com/questions/20234104/how-to-format-current-time-using-a-yyyymmddhhmmss-format
-			fmt.Print(f.CreatedAt.Format("2006-01-02 15:04:05"))
-			fmt.Print("|")
-			fmt.Print(f.CreatedBy)
-			fmt.Println()
-		}
+		act.print(files)
 	}
 
 	return true
 }
+
+// print prints a row per file. The machine-readable formats hold the same fields as the REST API,
+// including the ID, size, checksum and version the tables leave out.
+func (act *getFiles) print(files []vfs.File) {
+	if act.out.format() == FormatTable {
+		rows := make([][]interface{}, len(files))
+		for i, f := range files {
+			rows[i] = []interface{}{f.Name, f.Ext, f.Desc, f.CreatedAt, f.CreatedBy}
+		}
+		act.out.Rows([]string{"name", "extension", "description", "created_at", "created_by"}, rows)
+		return
+	}
+
+	rows := make([][]interface{}, len(files))
+	for i, f := range files {
+		rows[i] = []interface{}{f.ID, f.FolderID, f.Name, f.Ext, f.Desc, f.Size, f.Checksum, f.Version, f.CreatedBy, f.CreatedAt}
+	}
+	act.out.Rows([]string{"id", "folder_id", "name", "ext", "description", "size", "checksum", "version", "created_by", "created_at"}, rows)
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"virtual-file-system/vfs"
+)
+
+func TestGetFiles_Exec(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   []string
+	}{
+		{
+			name:   "01. it should print the fields of the tables.",
+			format: FormatTable,
+			want:   []string{"todo.txt|txt|The list|"},
+		},
+		{
+			name:   "02. it should print the fields of the REST API in the machine-readable formats.",
+			format: FormatCSV,
+			want: []string{
+				"id,folder_id,name,ext,description,size,checksum,version,created_by,created_at\n",
+				"1,1001,todo.txt,txt,The list,4,",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := vfs.New()
+			defer client.Close()
+
+			client.Register("luke", "")
+			client.CreateFolder("luke", "Work", "")
+			client.Upload("luke", "/Work", "todo.txt", "The list", strings.NewReader("todo"))
+
+			var buf bytes.Buffer
+			f := &Factory{Client: client, Output: &Output{Format: tt.format, W: &buf}}
+			args := []string{"get_files", "luke", "/Work"}
+			f.CreateAction(args).Exec(args)
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("getFiles.Exec() printed %q, want it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
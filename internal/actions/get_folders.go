@@ -1,47 +1,34 @@
 package actions
 
-import (
-	"fmt"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type getFolders struct {
-	folderService services.FolderService
+	client *vfs.Client
+	out    *Output
 }
 
-// Exec gets folders
+// Exec gets folders, the ones shared by other users are flagged with a trailing "shared" column.
 func (act *getFolders) Exec(args []string) bool {
-	if len(args) < 2 {
-		fmt.Println("Error - Missing arguments: get_folders {username} {sort_name | sort_time} {asc|dsc}")
-		return true
-	}
-
 	username := args[1]
 
+	// The folders are sorted in ascending order unless told otherwise.
 	var sortBy, ascOrDsc string
-	if len(args) == 4 {
-		sortBy = args[2]
+	if len(args) >= 3 {
+		sortBy, ascOrDsc = args[2], "asc"
+	}
+	if len(args) >= 4 {
 		ascOrDsc = args[3]
 	}
 
-	folders, err := act.folderService.GetAll(username, sortBy, ascOrDsc)
+	folders, err := act.client.Folders(username, sortBy, ascOrDsc)
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		for _, f := range folders {
-			fmt.Print(f.ID)
-			fmt.Print("|")
-			fmt.Print(f.Name)
-			fmt.Print("|")
-			fmt.Print(f.Description)
-			fmt.Print("|")
-
-			// https://stackoverflow.com/questions/20234104/how-to-format-current-time-using-a-yyyymmddhhmmss-format
-			fmt.Print(f.CreatedAt.Format("2006-01-02 15:04:05"))
-			fmt.Print("|")
-			fmt.Print(f.CreatedBy)
-			fmt.Println()
+		rows := make([][]interface{}, len(folders))
+		for i, f := range folders {
+			rows[i] = []interface{}{f.ID, f.Name, f.Description, f.CreatedAt, f.CreatedBy, !act.client.Owns(username, f)}
 		}
+		act.out.Rows([]string{"id", "name", "description", "created_at", "created_by", "shared"}, rows)
 	}
 
 	return true
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"virtual-file-system/vfs"
+)
+
+func TestGetFolders_Exec(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     []string
+	}{
+		{
+			name:     "01. it should not mark the folders created by a grantee under a folder the user owns as shared.",
+			username: "luke",
+			want:     []string{"luke,false\n", "mark,false\n"},
+		},
+		{
+			name:     "02. it should mark the folders shared with the user as shared.",
+			username: "mark",
+			want:     []string{"luke,true\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := vfs.New()
+			defer client.Close()
+
+			client.Register("luke", "")
+			client.Register("mark", "")
+			client.CreateFolder("luke", "Work", "")
+			client.Share("luke", "/Work", "mark", vfs.ReadWrite)
+			client.CreateFolder("mark", "1001/Reports", "")
+
+			var buf bytes.Buffer
+			f := &Factory{Client: client, Output: &Output{Format: FormatCSV, W: &buf}}
+			args := []string{"get_folders", tt.username}
+			f.CreateAction(args).Exec(args)
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("getFolders.Exec() printed %q, want it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
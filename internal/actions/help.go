@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"virtual-file-system/vfs"
+)
+
+type help struct {
+	out *Output
+}
+
+// Exec lists the commands with their usage, or describes the given command with its aliases and flags,
+// including the flags its arguments may be given by.
+func (act *help) Exec(args []string) bool {
+	if len(args) < 2 {
+		act.print(Commands(), false)
+		return true
+	}
+
+	cmd := LookupCommand(args[1])
+	if cmd == nil {
+		act.out.Error(&vfs.Error{Op: "help", Kind: vfs.Invalid, Entity: "command", ID: args[1], Err: errors.New("unknown command")})
+		return true
+	}
+
+	act.print([]*Command{cmd}, true)
+
+	return true
+}
+
+// print prints the usage and the help of the commands, along with their aliases and flags in detail.
+// The machine-readable formats print a row per command.
+func (act *help) print(cmds []*Command, detail bool) {
+	if act.out.format() != FormatTable {
+		rows := make([][]interface{}, len(cmds))
+		for i, cmd := range cmds {
+			rows[i] = []interface{}{cmd.Name, strings.Join(cmd.Aliases, ","), cmd.Usage(), cmd.Help}
+		}
+		act.out.Rows([]string{"command", "aliases", "usage", "help"}, rows)
+		return
+	}
+
+	w := act.out.writer()
+	for _, cmd := range cmds {
+		fmt.Fprintln(w, cmd.Usage())
+		fmt.Fprintln(w, "    "+cmd.Help)
+		if !detail {
+			continue
+		}
+
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintln(w, "    aliases: "+strings.Join(cmd.Aliases, ", "))
+		}
+		for _, arg := range cmd.Specs(true) {
+			if arg.Flag == "" {
+				continue
+			}
+
+			value := arg.Name
+			if len(arg.Choices) > 0 {
+				value = strings.Join(arg.Choices, "|")
+			}
+			fmt.Fprintf(w, "    %s=%s\n", arg.Flag, value)
+		}
+		for _, flag := range cmd.Flags {
+			fmt.Fprintf(w, "    %s: %s\n", flag.Name, flag.Help)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type listTrash struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec lists the deleted items of a user.
+func (act *listTrash) Exec(args []string) bool {
+	items, err := act.client.Trash(args[1])
+	if err != nil {
+		act.out.Error(err)
+		return true
+	}
+
+	rows := make([][]interface{}, len(items))
+	for i, item := range items {
+		kind := "file"
+		if len(item.Folders) > 0 {
+			kind = "folder"
+		}
+
+		rows[i] = []interface{}{item.ID, kind, item.Path, item.DeletedAt}
+	}
+	act.out.Rows([]string{"id", "kind", "path", "deleted_at"}, rows)
+
+	return true
+}
@@ -0,0 +1,29 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type listVersions struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec lists the revisions of a file
+func (act *listVersions) Exec(args []string) bool {
+	username := args[1]
+	fileName := args[3]
+	folderRef := args[2]
+
+	versions, err := act.client.Versions(username, folderRef, fileName)
+	if err != nil {
+		act.out.Error(err)
+		return true
+	}
+
+	rows := make([][]interface{}, len(versions))
+	for i, v := range versions {
+		rows[i] = []interface{}{v.Number, v.CreatedAt, v.CreatedBy, v.Size}
+	}
+	act.out.Rows([]string{"version", "created_at", "created_by", "size"}, rows)
+
+	return true
+}
@@ -0,0 +1,28 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type login struct {
+	client  *vfs.Client
+	session *Session
+	out     *Output
+}
+
+// Exec logs the user in, so the following commands are run on behalf of that user.
+func (act *login) Exec(args []string) bool {
+	username := args[1]
+
+	var password string
+	if len(args) >= 3 {
+		password = args[2]
+	}
+
+	if err := act.client.Authenticate(username, password); err != nil {
+		act.out.Error(err)
+	} else {
+		act.session.Username = username
+		act.out.Success()
+	}
+
+	return true
+}
@@ -0,0 +1,24 @@
+package actions
+
+import (
+	"errors"
+	"virtual-file-system/vfs"
+)
+
+type logout struct {
+	session *Session
+	out     *Output
+}
+
+// Exec logs the session user out.
+func (act *logout) Exec(args []string) bool {
+	if act.session.Username == "" {
+		act.out.Error(&vfs.Error{Op: "logout", Kind: vfs.Conflict, Err: errors.New("nobody is logged in")})
+		return true
+	}
+
+	act.session.Username = ""
+	act.out.Success()
+
+	return true
+}
@@ -0,0 +1,265 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"virtual-file-system/vfs"
+)
+
+// Format is how the actions print their results.
+type Format string
+
+const (
+	// FormatTable prints pipe-delimited lines for people, it is the default.
+	FormatTable Format = "table"
+
+	// FormatJSON prints a JSON document per command: an object, an array of objects for listings,
+	// or {"error": {...}} describing the failure.
+	FormatJSON Format = "json"
+
+	// FormatCSV prints comma-separated values with a header line per command.
+	FormatCSV Format = "csv"
+
+	// FormatTSV prints tab-separated values with a header line per command, quoted like CSV when needed.
+	FormatTSV Format = "tsv"
+)
+
+// Formats lists the supported output formats.
+var Formats = []Format{FormatTable, FormatJSON, FormatCSV, FormatTSV}
+
+// ParseFormat returns the format with given name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("output format should be one of %s", strings.Join(formatNames(), ", "))
+}
+
+// Output prints the results of the actions in the chosen format.
+// Its zero value prints tables to the standard output.
+type Output struct {
+	// Format is the format of the results, FormatTable if empty.
+	Format Format
+
+	// W is where the results are printed, the standard output if nil.
+	W io.Writer
+
+	// err is the latest failure printed.
+	err error
+}
+
+// timeLayout is how times are printed in tables.
+const timeLayout = "2006-01-02 15:04:05"
+
+func (out *Output) format() Format {
+	if out.Format == "" {
+		return FormatTable
+	}
+
+	return out.Format
+}
+
+func (out *Output) writer() io.Writer {
+	if out.W == nil {
+		return os.Stdout
+	}
+
+	return out.W
+}
+
+// Success reports an action completed without anything else to tell.
+func (out *Output) Success() {
+	if out.format() == FormatTable {
+		fmt.Fprintln(out.writer(), "Success")
+		return
+	}
+
+	out.Object([]string{"status"}, []interface{}{"success"})
+}
+
+// Object prints a single result made of the fields, named by the columns.
+// Tables only show the fields, e.g. the ID of a created folder.
+func (out *Output) Object(columns []string, fields []interface{}) {
+	if out.format() == FormatJSON {
+		out.writeJSON(jsonObject(columns, fields))
+		return
+	}
+
+	out.Rows(columns, [][]interface{}{fields})
+}
+
+// Rows prints a listing, each row holding the fields named by the columns.
+// Tables print a line per row with the fields separated by "|", a true boolean field is printed as its column name
+// and a false one is left out, e.g. the "shared" flag of a folder.
+func (out *Output) Rows(columns []string, rows [][]interface{}) {
+	w := out.writer()
+
+	switch out.format() {
+	case FormatJSON:
+		objects := make([]json.RawMessage, len(rows))
+		for i, fields := range rows {
+			objects[i] = jsonObject(columns, fields)
+		}
+		out.writeJSON(objects)
+	case FormatCSV, FormatTSV:
+		cw := csv.NewWriter(w)
+		if out.format() == FormatTSV {
+			cw.Comma = '\t'
+		}
+
+		cw.Write(columns)
+		for _, fields := range rows {
+			record := make([]string, len(fields))
+			for i, field := range fields {
+				record[i] = textField(field)
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+	default:
+		for _, fields := range rows {
+			line := make([]string, 0, len(fields))
+			for i, field := range fields {
+				switch v := field.(type) {
+				case bool:
+					if v {
+						line = append(line, columns[i])
+					}
+				case time.Time:
+					line = append(line, v.Format(timeLayout))
+				default:
+					line = append(line, fmt.Sprint(v))
+				}
+			}
+			fmt.Fprintln(w, strings.Join(line, "|"))
+		}
+	}
+}
+
+// Content prints the content of a file as is, tables end it with a line break.
+func (out *Output) Content(content io.Reader) error {
+	if _, err := io.Copy(out.writer(), content); err != nil {
+		return err
+	}
+
+	if out.format() == FormatTable {
+		fmt.Fprintln(out.writer())
+	}
+
+	return nil
+}
+
+// Error reports the action failed with err.
+// The machine-readable formats describe the failure with its kind, operation and entity, see errorObject.
+func (out *Output) Error(err error) {
+	out.err = err
+
+	if out.format() == FormatTable {
+		fmt.Fprintln(out.writer(), "Error - ", err)
+		return
+	}
+
+	e := errorObject{Message: err.Error(), Kind: vfs.KindOf(err).String()}
+
+	var vfsErr *vfs.Error
+	if errors.As(err, &vfsErr) {
+		e.Op = vfsErr.Op
+		e.Entity = vfsErr.Entity
+		e.ID = vfsErr.ID
+	}
+
+	out.writeError(e)
+}
+
+// Usage reports the action was given wrong arguments, described by the message.
+func (out *Output) Usage(message string) {
+	out.err = &vfs.Error{Kind: vfs.Invalid, Err: errors.New(message)}
+
+	if out.format() == FormatTable {
+		fmt.Fprintln(out.writer(), "Error - "+message)
+		return
+	}
+
+	out.writeError(errorObject{Message: message, Kind: vfs.Invalid.String()})
+}
+
+// Err returns the latest failure printed, or nil if every action succeeded so far.
+func (out *Output) Err() error {
+	return out.err
+}
+
+// errorObject is a failure as printed in the machine-readable formats.
+type errorObject struct {
+	// Message describes the failure, as printed in tables.
+	Message string `json:"message"`
+
+	// Kind is the category of the failure, such as "not found", see vfs.Kind.
+	Kind string `json:"kind"`
+
+	// Op is the operation that failed, if known.
+	Op string `json:"op,omitempty"`
+
+	// Entity and ID tell which user, folder or file the failure is about, if known.
+	Entity string `json:"entity,omitempty"`
+	ID     string `json:"id,omitempty"`
+}
+
+func (out *Output) writeError(e errorObject) {
+	if out.format() == FormatJSON {
+		out.writeJSON(struct {
+			Error errorObject `json:"error"`
+		}{e})
+		return
+	}
+
+	out.Rows([]string{"error", "kind", "op", "entity", "id"}, [][]interface{}{{e.Message, e.Kind, e.Op, e.Entity, e.ID}})
+}
+
+func (out *Output) writeJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		// Only plain values are printed, so this never happens.
+		panic(err)
+	}
+
+	fmt.Fprintln(out.writer(), string(data))
+}
+
+// jsonObject returns the JSON object made of the fields, keeping the order of the columns.
+func jsonObject(columns []string, fields []interface{}) json.RawMessage {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(column)
+		value, _ := json.Marshal(fields[i])
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes()
+}
+
+// textField returns the field as printed in comma or tab separated values.
+func textField(field interface{}) string {
+	if t, ok := field.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(field)
+}
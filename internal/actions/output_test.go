@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+	"virtual-file-system/vfs"
+)
+
+func TestOutput(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"id", "name", "created_at", "shared"}
+	rows := [][]interface{}{
+		{1001, "Work", createdAt, false},
+		{1002, `a|b, "c"`, createdAt, true},
+	}
+	notFound := &vfs.Error{Op: "delete_folder", Kind: vfs.NotFound, Entity: "folder", ID: "/Nope", Err: errors.New("folder does not exist")}
+
+	tests := []struct {
+		name   string
+		format Format
+		print  func(out *Output)
+		want   string
+	}{
+		{
+			name:   "01. it should print pipe-delimited rows with the set flags in tables.",
+			format: FormatTable,
+			print:  func(out *Output) { out.Rows(columns, rows) },
+			want:   "1001|Work|2026-01-02 03:04:05\n1002|a|b, \"c\"|2026-01-02 03:04:05|shared\n",
+		},
+		{
+			name:   "02. it should print an array of objects in JSON.",
+			format: FormatJSON,
+			print:  func(out *Output) { out.Rows(columns, rows) },
+			want: `[{"id":1001,"name":"Work","created_at":"2026-01-02T03:04:05Z","shared":false},` +
+				`{"id":1002,"name":"a|b, \"c\"","created_at":"2026-01-02T03:04:05Z","shared":true}]` + "\n",
+		},
+		{
+			name:   "03. it should print an empty array in JSON when there are no rows.",
+			format: FormatJSON,
+			print:  func(out *Output) { out.Rows(columns, nil) },
+			want:   "[]\n",
+		},
+		{
+			name:   "04. it should quote the values in CSV.",
+			format: FormatCSV,
+			print:  func(out *Output) { out.Rows(columns, rows) },
+			want:   "id,name,created_at,shared\n1001,Work,2026-01-02T03:04:05Z,false\n1002,\"a|b, \"\"c\"\"\",2026-01-02T03:04:05Z,true\n",
+		},
+		{
+			name:   "05. it should separate the values with tabs in TSV.",
+			format: FormatTSV,
+			print:  func(out *Output) { out.Object([]string{"id"}, []interface{}{1001}) },
+			want:   "id\n1001\n",
+		},
+		{
+			name:   "06. it should print the error message in tables.",
+			format: FormatTable,
+			print:  func(out *Output) { out.Error(notFound) },
+			want:   "Error -  folder does not exist\n",
+		},
+		{
+			name:   "07. it should print a structured error in JSON.",
+			format: FormatJSON,
+			print:  func(out *Output) { out.Error(notFound) },
+			want:   `{"error":{"message":"folder does not exist","kind":"not found","op":"delete_folder","entity":"folder","id":"/Nope"}}` + "\n",
+		},
+		{
+			name:   "08. it should print usage errors as invalid in JSON.",
+			format: FormatJSON,
+			print:  func(out *Output) { out.Usage("Missing arguments: list_trash {username}") },
+			want:   `{"error":{"message":"Missing arguments: list_trash {username}","kind":"invalid"}}` + "\n",
+		},
+		{
+			name:   "09. it should print the success as a status in CSV.",
+			format: FormatCSV,
+			print:  func(out *Output) { out.Success() },
+			want:   "status\nsuccess\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.print(&Output{Format: tt.format, W: &buf})
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    Format
+		wantErr bool
+	}{
+		{
+			name: "01. it should ignore the case of the name.",
+			arg:  "JSON",
+			want: FormatJSON,
+		},
+		{
+			name:    "02. it should return error for unknown formats.",
+			arg:     "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
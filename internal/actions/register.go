@@ -1,27 +1,25 @@
 package actions
 
-import (
-	"fmt"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type register struct {
-	userService services.UserService
+	client *vfs.Client
+	out    *Output
 }
 
 // Exec registers the user and returns true regardless of errors
 func (act *register) Exec(args []string) bool {
-	if len(args) != 2 {
-		fmt.Println("Error - Missing arguments: register {username}.")
-		return true
-	}
-
 	username := args[1]
 
-	if err := act.userService.Register(username); err != nil {
-		fmt.Println("Error - ", err)
+	var password string
+	if len(args) == 3 {
+		password = args[2]
+	}
+
+	if err := act.client.Register(username, password); err != nil {
+		act.out.Error(err)
 	} else {
-		fmt.Println("Success")
+		act.out.Success()
 	}
 
 	return true
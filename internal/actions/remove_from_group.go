@@ -0,0 +1,23 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type removeFromGroup struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec removes a user from a group.
+func (act *removeFromGroup) Exec(args []string) bool {
+	username := args[1]
+	groupName := args[2]
+	member := args[3]
+
+	if err := act.client.RemoveFromGroup(username, groupName, member); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -1,36 +1,24 @@
 package actions
 
-import (
-	"fmt"
-	"strconv"
-	"virtual-file-system/internal/services"
-)
+import "virtual-file-system/vfs"
 
 type renameFolder struct {
-	folderService services.FolderService
+	client *vfs.Client
+	out    *Output
 }
 
 // Exec renames a folder
 func (act *renameFolder) Exec(args []string) bool {
-	if len(args) < 4 {
-		fmt.Println("Error - Missing arguments: rename_folders {username} {folder_id} {new_folder_name}")
-		return true
-	}
-
 	username := args[1]
 	newFolderName := args[3]
 
-	folderID, err := strconv.Atoi(args[2])
-	if err != nil {
-		fmt.Println("Error - {folder_id} should be integer")
-		return true
-	}
+	folderRef := args[2]
 
-	err = act.folderService.Rename(folderID, newFolderName, username)
+	err := act.client.RenameFolder(username, folderRef, newFolderName)
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		fmt.Println("Success")
+		act.out.Success()
 	}
 
 	return true
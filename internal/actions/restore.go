@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"strconv"
+	"virtual-file-system/vfs"
+)
+
+type restore struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec puts a deleted item back where it was.
+func (act *restore) Exec(args []string) bool {
+	username := args[1]
+	id, err := strconv.Atoi(args[2])
+	if err != nil {
+		act.out.Usage("item id should be a number")
+		return true
+	}
+
+	if err := act.client.Restore(username, id); err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -0,0 +1,33 @@
+package actions
+
+import (
+	"strconv"
+	"virtual-file-system/vfs"
+)
+
+type restoreVersion struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec rolls a file back to one of its revisions
+func (act *restoreVersion) Exec(args []string) bool {
+	username := args[1]
+	fileName := args[3]
+	folderRef := args[2]
+
+	number, err := strconv.Atoi(args[4])
+	if err != nil {
+		act.out.Usage("version should be a number")
+		return true
+	}
+
+	_, err = act.client.RestoreVersion(username, folderRef, fileName, number)
+	if err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
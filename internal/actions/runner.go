@@ -0,0 +1,238 @@
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"virtual-file-system/vfs"
+
+	"github.com/google/shlex"
+)
+
+// Runner runs command lines one after another, as typed in the shell or read from a script.
+//
+// Besides the commands, a line may be
+//
+//	# a comment, also allowed at the end of a command
+//	set -e                             stop at the first failed line, "set +e" carries on again
+//	work=1001                          set the variable work, used as $work or ${work} in later lines
+//	work=$(create_folder user1 Work)   set the variable work to the result of the command, e.g. the folder ID
+type Runner struct {
+	factory *Factory
+
+	vars        map[string]string
+	stopOnError bool
+	stopped     bool
+	failures    []Failure
+}
+
+// Failure is a line that failed.
+type Failure struct {
+	// Line is the number of the line, starting from 1.
+	Line int
+
+	// Text is the line as given.
+	Text string
+
+	// Err tells why the line failed.
+	Err error
+}
+
+// NewRunner returns a Runner creating the actions with the factory.
+func NewRunner(factory *Factory) *Runner {
+	return &Runner{factory: factory, vars: make(map[string]string)}
+}
+
+var (
+	// assignment matches "name=value", captureCommand matches the "$(command)" value capturing the result of a command,
+	// which may be followed by a comment.
+	assignment     = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	captureCommand = regexp.MustCompile(`^\$\((.*?)\)\s*(?:#.*)?$`)
+
+	// variable matches "$name" and "${name}".
+	variable = regexp.MustCompile(`\$(?:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\})`)
+)
+
+// Run runs the line with given number and returns false if no more lines should be run,
+// either because of "exit" or because the line failed after "set -e".
+func (r *Runner) Run(number int, line string) bool {
+	out := r.factory.output()
+
+	// The failure of this line is told apart from the earlier ones, and restored if the line succeeds.
+	earlier := out.err
+	out.err = nil
+
+	next := r.run(strings.TrimSpace(line))
+
+	if out.err == nil {
+		out.err = earlier
+		return next
+	}
+
+	r.failures = append(r.failures, Failure{Line: number, Text: line, Err: out.err})
+	r.stopped = r.stopOnError
+
+	return next && !r.stopOnError
+}
+
+func (r *Runner) run(line string) bool {
+	out := r.factory.output()
+
+	if m := assignment.FindStringSubmatch(line); m != nil {
+		name, value := m[1], m[2]
+		if c := captureCommand.FindStringSubmatch(value); c != nil {
+			return r.capture(name, c[1])
+		}
+
+		args, err := r.split(value)
+		if err != nil {
+			out.Error(err)
+			return true
+		}
+
+		r.vars[name] = strings.Join(args, " ")
+		return true
+	}
+
+	args, err := r.split(line)
+	if err != nil {
+		out.Error(err)
+		return true
+	}
+
+	// Blank lines and comments.
+	if len(args) == 0 {
+		return true
+	}
+
+	if len(args) == 2 && args[0] == "set" && (args[1] == "-e" || args[1] == "+e") {
+		r.stopOnError = args[1] == "-e"
+		return true
+	}
+
+	return r.factory.CreateAction(args).Exec(args)
+}
+
+// capture runs the command and sets the variable to what it printed as a table, such as the ID of a created folder.
+// The failure of the command is printed as usual.
+func (r *Runner) capture(name string, command string) bool {
+	args, err := r.split(command)
+	if err != nil {
+		r.factory.output().Error(err)
+		return true
+	}
+
+	if len(args) == 0 {
+		r.factory.output().Usage("Missing command in " + name + "=$(...)")
+		return true
+	}
+
+	out := r.factory.output()
+	format, w := out.Format, out.W
+
+	var buf bytes.Buffer
+	out.Format, out.W = FormatTable, &buf
+	next := r.factory.CreateAction(args).Exec(args)
+	out.Format, out.W = format, w
+
+	if err := out.err; err != nil {
+		if out.format() == FormatTable {
+			out.writer().Write(buf.Bytes())
+		} else {
+			out.Error(err)
+		}
+		return next
+	}
+
+	r.vars[name] = strings.TrimSpace(buf.String())
+
+	return next
+}
+
+// split expands the variables of the line, then splits it into arguments the way a shell does.
+func (r *Runner) split(line string) ([]string, error) {
+	line, err := r.expand(line)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := shlex.Split(line)
+	if err != nil {
+		return nil, &vfs.Error{Kind: vfs.Invalid, Err: fmt.Errorf("syntax error: %v", err)}
+	}
+
+	return args, nil
+}
+
+// expand replaces the variables of the line with their value, quoted so each one stays within its argument.
+// Like in a shell, the text within single quotes and "\$" are left as they are, and so are comments.
+func (r *Runner) expand(line string) (string, error) {
+	var b strings.Builder
+
+	// quote is the quote of the text being read, 0 outside quotes.
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(line):
+			b.WriteString(line[i : i+2])
+			i++
+			continue
+		case (c == '\'' || c == '"') && (quote == 0 || quote == c):
+			if quote == 0 {
+				quote = c
+			} else {
+				quote = 0
+			}
+		case c == '#' && quote == 0 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			b.WriteString(line[i:])
+			return b.String(), nil
+		case c == '$' && quote != '\'':
+			if m := variable.FindStringSubmatchIndex(line[i:]); m != nil && m[0] == 0 {
+				name := line[i+m[2] : i+m[3]]
+				if m[2] < 0 {
+					name = line[i+m[4] : i+m[5]]
+				}
+				value, ok := r.vars[name]
+				if !ok {
+					return "", &vfs.Error{Kind: vfs.Invalid, Err: fmt.Errorf("variable %s is not set", name)}
+				}
+
+				if quote == '"' {
+					b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+				} else {
+					b.WriteString("'" + strings.ReplaceAll(value, "'", `'\''`) + "'")
+				}
+				i += m[1] - 1
+				continue
+			}
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String(), nil
+}
+
+// Failures returns the lines that failed so far.
+func (r *Runner) Failures() []Failure {
+	return r.failures
+}
+
+// WriteSummary writes the lines that failed out of the given number of lines run, if any.
+func (r *Runner) WriteSummary(w io.Writer, lines int) {
+	if len(r.failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%d of %d lines failed:\n", len(r.failures), lines)
+	for _, f := range r.failures {
+		fmt.Fprintf(w, "  line %d: %s: %v\n", f.Line, strings.TrimSpace(f.Text), f.Err)
+	}
+
+	if r.stopped {
+		fmt.Fprintf(w, "stopped at line %d, see set -e\n", r.failures[len(r.failures)-1].Line)
+	}
+}
@@ -0,0 +1,142 @@
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"virtual-file-system/vfs"
+)
+
+func TestRunner_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		lines       []string
+		want        string
+		wantSummary string
+	}{
+		{
+			name: "01. it should skip blank lines and comments.",
+			lines: []string{
+				"# set up a user",
+				"",
+				"register luke # no password",
+			},
+			want: "Success\n",
+		},
+		{
+			name: "02. it should capture the result of a command into a variable.",
+			lines: []string{
+				"register luke",
+				"work=$(create_folder luke Work 'The working files') # the folder ID",
+				"create_folder luke ${work}/Reports",
+				"name=Reports",
+				"get_files luke $work/$name",
+			},
+			want: "Success\n1002\n",
+		},
+		{
+			name:   "03. it should print the failure of a captured command in the chosen format.",
+			format: FormatJSON,
+			lines: []string{
+				"work=$(create_folder luke Work)",
+			},
+			want: `{"error":{"message":"user does not exist","kind":"not found","op":"create_folder","entity":"user","id":"luke"}}` + "\n",
+			wantSummary: "1 of 1 lines failed:\n" +
+				"  line 1: work=$(create_folder luke Work): user does not exist\n",
+		},
+		{
+			name: "04. it should carry on after syntax errors and unknown variables.",
+			lines: []string{
+				"create_folder luke 'Work",
+				"get_folders $user",
+				"register luke",
+			},
+			want: "Error -  syntax error: EOF found when expecting closing quote\n" +
+				"Error -  variable user is not set\n" +
+				"Success\n",
+			wantSummary: "2 of 3 lines failed:\n" +
+				"  line 1: create_folder luke 'Work: syntax error: EOF found when expecting closing quote\n" +
+				"  line 2: get_folders $user: variable user is not set\n",
+		},
+		{
+			name: "05. it should stop at the first failed line after set -e.",
+			lines: []string{
+				"register luke",
+				"delete_folder luke /Work",
+				"set -e",
+				"delete_folder luke /Work",
+				"register mark",
+			},
+			want: "Success\nError -  folder does not exist\nError -  folder does not exist\n",
+			wantSummary: "2 of 4 lines failed:\n" +
+				"  line 2: delete_folder luke /Work: folder does not exist\n" +
+				"  line 4: delete_folder luke /Work: folder does not exist\n" +
+				"stopped at line 4, see set -e\n",
+		},
+		{
+			name: "06. it should carry on again after set +e.",
+			lines: []string{
+				"set -e",
+				"set +e",
+				"delete_folder luke /Work",
+				"register luke",
+			},
+			want: "Error -  user does not exist\nSuccess\n",
+			wantSummary: "1 of 4 lines failed:\n" +
+				"  line 3: delete_folder luke /Work: user does not exist\n",
+		},
+		{
+			name: "07. it should leave the text within single quotes and the escaped dollars as they are.",
+			lines: []string{
+				"register luke",
+				"create_folder luke 'costs $USD today'",
+				`create_folder luke costs\ \$USD\ today`,
+				`create_folder luke "costs \$USD today"`,
+			},
+			want: "Success\n1001\nError -  folder name already exists\nError -  folder name already exists\n",
+			wantSummary: "2 of 4 lines failed:\n" +
+				`  line 3: create_folder luke costs\ \$USD\ today: folder name already exists` + "\n" +
+				`  line 4: create_folder luke "costs \$USD today": folder name already exists` + "\n",
+		},
+		{
+			name: "08. it should expand the variables within double quotes, keeping each value within its argument.",
+			lines: []string{
+				"register luke",
+				`name='Q1 "draft" $reports'`,
+				`create_folder luke "$name"`,
+				"create_folder luke $name # not $set",
+			},
+			want: "Success\n1001\nError -  folder name already exists\n",
+			wantSummary: "1 of 4 lines failed:\n" +
+				"  line 4: create_folder luke $name # not $set: folder name already exists\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := vfs.New()
+			defer client.Close()
+
+			var buf bytes.Buffer
+			runner := NewRunner(&Factory{Client: client, Output: &Output{Format: tt.format, W: &buf}})
+
+			lines := 0
+			for i, line := range tt.lines {
+				lines++
+				if !runner.Run(i+1, line) {
+					break
+				}
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Runner.Run() printed %q, want %q", got, tt.want)
+			}
+
+			var summary strings.Builder
+			runner.WriteSummary(&summary, lines)
+			if got := summary.String(); got != tt.wantSummary {
+				t.Errorf("Runner.WriteSummary() = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package actions
+
+import (
+	"fmt"
+	"virtual-file-system/vfs"
+)
+
+// Session holds the user logged in to the shell.
+type Session struct {
+	// Username is the logged in user, empty if nobody is logged in.
+	Username string
+}
+
+// sessionUser runs actions that act on behalf of a user.
+// While a user is logged in, the {username} argument is dropped from the command and the session user is used instead.
+// Otherwise the {username} argument is only trusted for users registered without a password.
+type sessionUser struct {
+	action  Action
+	session *Session
+	client  *vfs.Client
+	out     *Output
+}
+
+// Exec runs the wrapped action as the session user.
+func (act *sessionUser) Exec(args []string) bool {
+	if act.session.Username != "" {
+		withUser := make([]string, 0, len(args)+1)
+		withUser = append(withUser, args[0], act.session.Username)
+		withUser = append(withUser, args[1:]...)
+
+		return act.action.Exec(withUser)
+	}
+
+	// The {username} argument comes first, see checked.
+	if len(args) > 1 && act.client.HasPassword(args[1]) {
+		act.out.Error(&vfs.Error{
+			Op:     args[0],
+			Kind:   vfs.Unauthenticated,
+			Entity: "user",
+			ID:     args[1],
+			Err:    fmt.Errorf("%s is protected by a password, please login first", args[1]),
+		})
+		return true
+	}
+
+	return act.action.Exec(args)
+}
+
+// SetFlags hands the flags over to the wrapped action, see flagged.
+func (act *sessionUser) SetFlags(flags []string) {
+	if action, ok := act.action.(flagged); ok {
+		action.SetFlags(flags)
+	}
+}
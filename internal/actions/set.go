@@ -0,0 +1,14 @@
+package actions
+
+type set struct {
+	out *Output
+}
+
+// Exec changes a setting of the shell, the only one being the output format.
+func (act *set) Exec(args []string) bool {
+	// The setting and the format are checked against the choices of the command.
+	act.out.Format = Format(args[2])
+	act.out.Success()
+
+	return true
+}
@@ -0,0 +1,25 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type shareFolder struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec shares a folder with another user.
+func (act *shareFolder) Exec(args []string) bool {
+	username := args[1]
+	grantee := args[3]
+	access := args[4]
+	folderRef := args[2]
+
+	err := act.client.Share(username, folderRef, grantee, access)
+	if err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
@@ -1,11 +1,26 @@
 package actions
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"virtual-file-system/vfs"
+)
 
-type unknown struct{}
+type unknown struct {
+	out *Output
+}
 
 // Exec does nothing and simply returns true to the caller.
 func (act *unknown) Exec(args []string) bool {
-	fmt.Println("Unknown command: ", args)
+	message := "unknown command: " + strings.Join(args, " ")
+	act.out.err = &vfs.Error{Kind: vfs.Invalid, Err: errors.New(message)}
+
+	if act.out.format() == FormatTable {
+		fmt.Fprintln(act.out.writer(), "Unknown command: ", args)
+		return true
+	}
+
+	act.out.writeError(errorObject{Message: message, Kind: vfs.Invalid.String()})
 	return true
 }
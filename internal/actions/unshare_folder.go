@@ -0,0 +1,24 @@
+package actions
+
+import "virtual-file-system/vfs"
+
+type unshareFolder struct {
+	client *vfs.Client
+	out    *Output
+}
+
+// Exec stops sharing a folder with another user.
+func (act *unshareFolder) Exec(args []string) bool {
+	username := args[1]
+	grantee := args[3]
+	folderRef := args[2]
+
+	err := act.client.Unshare(username, folderRef, grantee)
+	if err != nil {
+		act.out.Error(err)
+	} else {
+		act.out.Success()
+	}
+
+	return true
+}
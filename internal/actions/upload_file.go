@@ -1,41 +1,56 @@
 package actions
 
 import (
-	"fmt"
-	"strconv"
-	"virtual-file-system/internal/services"
+	"io"
+	"os"
+	"virtual-file-system/vfs"
 )
 
 type uploadFile struct {
-	fileService services.FileService
+	flagSet
+	client *vfs.Client
+	stdin  io.Reader
+	out    *Output
 }
 
 // Exec uploads a file
 func (act *uploadFile) Exec(args []string) bool {
-	//upload_file {username} {folder_id} {file_name} {description}
-	if len(args) < 4 {
-		fmt.Println("Error - Missing arguments: upload_file {username} {folder_id} {file_name} {description}")
-		return true
-	}
-
+	newVersion := act.has("--new-version")
 	username := args[1]
 	fileName := args[3]
-	folderID, err := strconv.Atoi(args[2])
-	if err != nil {
-		fmt.Println("Error - {folder_id} should be integer")
-		return true
-	}
+	folderRef := args[2]
 
 	var description string
-	if len(args) == 5 {
+	if len(args) >= 5 {
 		description = args[4]
 	}
 
-	err = act.fileService.Upload(username, folderID, fileName, description)
+	// Without a source the file is created empty, "-" reads the content from stdin.
+	var content io.Reader
+	if len(args) >= 6 {
+		if args[5] == "-" {
+			content = act.stdin
+		} else {
+			f, err := os.Open(args[5])
+			if err != nil {
+				act.out.Error(err)
+				return true
+			}
+			defer f.Close()
+			content = f
+		}
+	}
+
+	var err error
+	if newVersion {
+		_, err = act.client.UploadVersion(username, folderRef, fileName, description, content)
+	} else {
+		_, err = act.client.Upload(username, folderRef, fileName, description, content)
+	}
 	if err != nil {
-		fmt.Println("Error - ", err)
+		act.out.Error(err)
 	} else {
-		fmt.Println("Success")
+		act.out.Success()
 	}
 
 	return true
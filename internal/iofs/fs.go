@@ -0,0 +1,294 @@
+// Package iofs exposes the folders and files of a user as an io/fs file system,
+// so they can be handed to standard library consumers such as http.FileServer, template.ParseFS or fs.WalkDir.
+package iofs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+// FS is a read-only view of the folders and files the user can read.
+// The root "." holds the folders the user created at the root, and paths are made of
+// folder names followed by a file name, e.g. "work/reports/q3.pdf".
+type FS struct {
+	folderService services.FolderService
+	fileService   services.FileService
+	username      string
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+)
+
+// New returns the file system of the user backed by the services of the factory.
+func New(factory *services.Factory, username string) *FS {
+	return &FS{
+		folderService: factory.GetFolderService(),
+		fileService:   factory.GetFileService(),
+		username:      username,
+	}
+}
+
+// Open opens the named file or folder.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	folder, file, err := fsys.locate("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		entries, err := fsys.readDir("open", name, folder)
+		if err != nil {
+			return nil, err
+		}
+
+		return &openDir{info: newFolderInfo(name, folder), entries: entries}, nil
+	}
+
+	data, err := fsys.read("open", name, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openFile{Reader: bytes.NewReader(data), info: newFileInfo(*file)}, nil
+}
+
+// ReadDir reads the named folder and returns its subfolders and files sorted by name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	folder, file, err := fsys.locate("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+
+	return fsys.readDir("readdir", name, folder)
+}
+
+// Stat returns the information of the named file or folder.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	folder, file, err := fsys.locate("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != nil {
+		return newFileInfo(*file), nil
+	}
+
+	return newFolderInfo(name, folder), nil
+}
+
+// ReadFile reads the content of the named file.
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	_, file, err := fsys.locate("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if file == nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+	}
+
+	return fsys.read("readfile", name, *file)
+}
+
+// locate returns the folder or the file with given name, both nil for the root.
+func (fsys *FS) locate(op string, name string) (*models.Folder, *models.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return nil, nil, nil
+	}
+
+	folder, err := fsys.folderService.Resolve(fsys.username, "/"+name)
+	if err == nil {
+		return folder, nil, nil
+	}
+	if !errors.Is(err, services.ErrNotFound) {
+		return nil, nil, pathError(op, name, err)
+	}
+
+	dir, base := path.Split(name)
+	if dir == "" {
+		// Only folders live at the root.
+		return nil, nil, pathError(op, name, err)
+	}
+
+	parent, err := fsys.folderService.Resolve(fsys.username, "/"+dir)
+	if err != nil {
+		return nil, nil, pathError(op, name, err)
+	}
+
+	files, err := fsys.fileService.GetAll(fsys.username, parent.ID, "", "")
+	if err != nil {
+		return nil, nil, pathError(op, name, err)
+	}
+
+	for _, file := range files {
+		if strings.EqualFold(file.Name, base) {
+			return nil, &file, nil
+		}
+	}
+
+	return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// readDir returns the entries under the folder, or under the root if the folder is nil.
+func (fsys *FS) readDir(op string, name string, folder *models.Folder) ([]fs.DirEntry, error) {
+	folders, err := fsys.folderService.GetAll(fsys.username, "sort_name", "asc")
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+
+	var entries []fs.DirEntry
+	for _, f := range folders {
+		if folder == nil {
+			// The root of the user holds the folders the user created there.
+			if f.ParentID != 0 || !strings.EqualFold(f.CreatedBy, fsys.username) {
+				continue
+			}
+		} else if f.ParentID != folder.ID {
+			continue
+		}
+
+		f := f
+		entries = append(entries, fs.FileInfoToDirEntry(newFolderInfo(f.Name, &f)))
+	}
+
+	if folder != nil {
+		files, err := fsys.fileService.GetAll(fsys.username, folder.ID, "", "")
+		if err != nil {
+			return nil, pathError(op, name, err)
+		}
+
+		for _, file := range files {
+			entries = append(entries, fs.FileInfoToDirEntry(newFileInfo(file)))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func (fsys *FS) read(op string, name string, file models.File) ([]byte, error) {
+	content, err := fsys.fileService.Open(fsys.username, file.FolderID, file.Name)
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+	defer content.Close()
+
+	return ioutil.ReadAll(content)
+}
+
+// pathError wraps the error returned by a service, translating it to the matching fs error.
+func pathError(op string, name string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		err = fs.ErrNotExist
+	case errors.Is(err, services.ErrPermissionDenied):
+		err = fs.ErrPermission
+	}
+
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fileInfo describes a folder or a file.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	sys     interface{}
+}
+
+// newFolderInfo returns the information of the folder, or of the root if folder is nil.
+func newFolderInfo(name string, folder *models.Folder) *fileInfo {
+	info := &fileInfo{name: path.Base(name), mode: fs.ModeDir | 0555}
+	if folder != nil {
+		info.modTime = folder.CreatedAt
+		info.sys = *folder
+	}
+
+	return info
+}
+
+func newFileInfo(file models.File) *fileInfo {
+	modTime := file.CreatedAt
+	if n := len(file.Versions); n > 0 {
+		modTime = file.Versions[n-1].CreatedAt
+	}
+
+	return &fileInfo{name: file.Name, size: file.Size, mode: 0444, modTime: modTime, sys: file}
+}
+
+func (info *fileInfo) Name() string       { return info.name }
+func (info *fileInfo) Size() int64        { return info.size }
+func (info *fileInfo) Mode() fs.FileMode  { return info.mode }
+func (info *fileInfo) ModTime() time.Time { return info.modTime }
+func (info *fileInfo) IsDir() bool        { return info.mode.IsDir() }
+
+// Sys returns the models.Folder or models.File described, nil for the root.
+func (info *fileInfo) Sys() interface{} { return info.sys }
+
+// openFile is an opened file, whose content is read in full when it is opened.
+type openFile struct {
+	*bytes.Reader
+	info *fileInfo
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openFile) Close() error               { return nil }
+
+// openDir is an opened folder.
+type openDir struct {
+	info    *fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir returns the next n entries of the folder, or all the remaining ones if n is not positive.
+func (d *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+
+	return remaining[:n], nil
+}
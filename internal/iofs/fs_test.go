@@ -0,0 +1,61 @@
+package iofs
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"virtual-file-system/internal/services"
+)
+
+func TestFS(t *testing.T) {
+	factory := &services.Factory{}
+	factory.GetUserService().Register("luke", "")
+	factory.GetUserService().Register("mark", "")
+
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+	reports, _ := factory.GetFolderService().Create("/Work/Reports", "luke", "")
+	factory.GetFolderService().Create("Empty", "luke", "")
+	factory.GetFolderService().Create("Private", "mark", "")
+	factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader("hello"))
+	factory.GetFileService().Upload("luke", reports.ID, "q3.pdf", "", strings.NewReader("report"))
+
+	fsys := New(factory, "luke")
+	if err := fstest.TestFS(fsys, "Work/1.tc", "Work/Reports/q3.pdf", "Empty"); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "01. it should read the file content.",
+			path: "Work/Reports/q3.pdf",
+			want: "report",
+		},
+		{
+			name:    "02. it should return error for the folders of other users.",
+			path:    "Private",
+			wantErr: true,
+		},
+		{
+			name:    "03. it should return error for invalid paths.",
+			path:    "/Work/1.tc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fsys.ReadFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FS.ReadFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("FS.ReadFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import "time"
 
 // File is the virtual file
 type File struct {
-	// Name should be included as an extension and should be uniqued.
+	// ID is the unique identifier, which stays the same even if the file is renamed or moved.
+	ID int
+
+	// Name should be included as an extension and should be uniqued within its folder, case insensitive.
 	Name string
 
 	// Ext is the file extension
@@ -16,9 +19,21 @@ type File struct {
 	// Desc of a folder is not a necessary field.
 	Desc string
 
+	// Size is the length of the file content in bytes.
+	Size int64
+
+	// Checksum is the hex encoded SHA-256 digest of the file content.
+	Checksum string
+
 	// CreatedAt is the time this file was created.
 	CreatedAt time.Time
 
 	// CreatedBy is the user that created this file.
 	CreatedBy string
+
+	// Version is the number of the current revision, starting from 1.
+	Version int
+
+	// Versions are the retained revisions, oldest first, the last one being the current revision.
+	Versions []FileVersion
 }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// FileVersion is a revision of the file content
+type FileVersion struct {
+	// Number is the revision number, which increases by 1 on every new revision of the file.
+	Number int
+
+	// Desc is the description of the file at the time of this revision.
+	Desc string
+
+	// Size is the length of the revision content in bytes.
+	Size int64
+
+	// Checksum is the hex encoded SHA-256 digest of the revision content.
+	Checksum string
+
+	// CreatedAt is the time this revision was uploaded.
+	CreatedAt time.Time
+
+	// CreatedBy is the user that uploaded this revision.
+	CreatedBy string
+}
@@ -7,7 +7,10 @@ type Folder struct {
 	// The unique identifier.
 	ID int
 
-	// Name should be uniqued and case insensitive.
+	// ParentID is the ID of the folder that this folder is under, 0 if it is at the root of its owner.
+	ParentID int
+
+	// Name should be uniqued among the siblings and case insensitive.
 	Name string
 
 	// Description of a folder is not a necessary field.
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	// AccessReadOnly lets the grantee list and download.
+	AccessReadOnly = "ro"
+
+	// AccessReadWrite lets the grantee change the content as well.
+	AccessReadWrite = "rw"
+)
+
+// Grant gives a user other than the owner, or a group, access to a folder and everything below it.
+type Grant struct {
+	// FolderID is the ID of the shared folder.
+	FolderID int
+
+	// Grantee is the user or the group the folder is shared with.
+	Grantee string
+
+	// Group is true if the grantee is a group.
+	Group bool
+
+	// Access is either AccessReadOnly or AccessReadWrite.
+	Access string
+
+	// GrantedBy is the user that shared the folder.
+	GrantedBy string
+
+	// GrantedAt is the time the folder was shared.
+	GrantedAt time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Group is a named set of users that folders can be shared with at once.
+type Group struct {
+	// Name is the identifier, which should be unique throughout the application, but case insensitive.
+	Name string
+
+	// Members are the names of the users in this group.
+	Members []string
+
+	// CreatedBy is the user that created this group, the only one allowed to change its members.
+	CreatedBy string
+
+	// CreatedAt is the time this group was created.
+	CreatedAt time.Time
+}
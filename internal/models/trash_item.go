@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TrashItem is a deleted folder or file kept in the recycle bin of the user who deleted it,
+// which the owner of the folder it was deleted from sees as well.
+type TrashItem struct {
+	// ID is the unique identifier of the item within the recycle bin.
+	ID int
+
+	// Path is where the item was before it was deleted.
+	Path string
+
+	// Folders are the deleted folder together with its subfolders, parents first. It is empty for a deleted file.
+	Folders []Folder
+
+	// Files are the deleted file, or the files that were under the deleted folders.
+	Files []File
+
+	// Grants are the grants that were on the deleted folders.
+	Grants []Grant
+
+	// DeletedBy is the user that deleted the item, who is able to restore it along with the owner of its folder.
+	DeletedBy string
+
+	// DeletedAt is the time the item was deleted.
+	DeletedAt time.Time
+}
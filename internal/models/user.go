@@ -4,4 +4,10 @@ package models
 type User struct {
 	// Name is the identifier, which should be unique throughout the application, but ​case insensitive.
 	Name string
+
+	// PasswordHash is the salted hash of the password, empty if the user registered without one.
+	PasswordHash string
+
+	// PasswordSalt is the random salt the password is hashed with.
+	PasswordSalt string
 }
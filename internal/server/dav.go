@@ -0,0 +1,708 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+// davPrefix is where the WebDAV tree is mounted, the collection right below it is the root of the user.
+const davPrefix = "/dav"
+
+// davNode is what a WebDAV path refers to: the root of the user, a folder, or a file.
+// The parent is the folder holding the folder or file, which is set for a missing one as well
+// so it can be created, and is nil at the root.
+type davNode struct {
+	path   string
+	folder *models.Folder
+	file   *models.File
+	parent *models.Folder
+}
+
+func (node davNode) isRoot() bool {
+	return node.path == "/"
+}
+
+func (node davNode) isCollection() bool {
+	return node.isRoot() || node.folder != nil
+}
+
+func (node davNode) exists() bool {
+	return node.isCollection() || node.file != nil
+}
+
+// serveDAV handles the WebDAV requests, mapping folders onto collections and files onto resources.
+// Folders are addressed by their path from the root of the authenticated user.
+func (s *Server) serveDAV(w http.ResponseWriter, r *http.Request) {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	node, err := s.davLocate(r.Context(), username, strings.TrimPrefix(r.URL.Path, davPrefix))
+	if err != nil {
+		if (r.Method == "MKCOL" || r.Method == http.MethodPut) && errors.Is(err, services.ErrNotFound) {
+			writeError(w, http.StatusConflict, "parent folder does not exist")
+			return
+		}
+
+		writeServiceError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1")
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, GET, HEAD, PUT, DELETE, MKCOL, MOVE, COPY, LOCK, UNLOCK")
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		s.davPropfind(w, r, username, node)
+	case http.MethodGet, http.MethodHead:
+		s.davGet(w, r, username, node)
+	case http.MethodPut:
+		s.davPut(w, r, username, node)
+	case http.MethodDelete:
+		s.davDelete(w, r, username, node)
+	case "MKCOL":
+		s.davMkcol(w, r, username, node)
+	case "MOVE", "COPY":
+		s.davMoveOrCopy(w, r, username, node)
+	case "LOCK":
+		s.davLock(w, r, node)
+	case "UNLOCK":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, "OPTIONS", "PROPFIND", "GET", "HEAD", "PUT", "DELETE", "MKCOL", "MOVE", "COPY", "LOCK", "UNLOCK")
+	}
+}
+
+// davLocate returns the node the path refers to.
+// An error is returned if the folder holding it does not exist or cannot be read by the user.
+func (s *Server) davLocate(ctx context.Context, username string, p string) (davNode, error) {
+	p = path.Clean("/" + p)
+	node := davNode{path: p}
+	if node.isRoot() {
+		return node, nil
+	}
+
+	if folder, err := s.folderService.ResolveContext(ctx, username, p); err == nil {
+		node.folder = folder
+		if folder.ParentID != 0 {
+			if node.parent, err = s.folderService.GetContext(ctx, folder.ParentID); err != nil {
+				return node, err
+			}
+		}
+		return node, nil
+	} else if !errors.Is(err, services.ErrNotFound) {
+		return node, err
+	}
+
+	dir, name := path.Split(p)
+	if dir == "/" {
+		// Only folders live at the root.
+		return node, nil
+	}
+
+	parent, err := s.folderService.ResolveContext(ctx, username, dir)
+	if err != nil {
+		return node, err
+	}
+	node.parent = parent
+
+	files, err := s.fileService.GetAllContext(ctx, username, parent.ID, "", "")
+	if err != nil {
+		return node, err
+	}
+
+	for _, file := range files {
+		if strings.EqualFold(file.Name, name) {
+			file := file
+			node.file = &file
+			break
+		}
+	}
+
+	return node, nil
+}
+
+// davChildren returns the nodes right under the collection.
+func (s *Server) davChildren(ctx context.Context, username string, node davNode) ([]davNode, error) {
+	folders, err := s.folderService.GetAllContext(ctx, username, "sort_name", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	var children []davNode
+	for _, folder := range folders {
+		folder := folder
+		if node.isRoot() {
+			// The root of the user holds the folders the user created there.
+			if folder.ParentID != 0 || !strings.EqualFold(folder.CreatedBy, username) {
+				continue
+			}
+		} else if folder.ParentID != node.folder.ID {
+			continue
+		}
+
+		children = append(children, davNode{path: path.Join(node.path, folder.Name), folder: &folder})
+	}
+
+	if node.isRoot() {
+		return children, nil
+	}
+
+	files, err := s.fileService.GetAllContext(ctx, username, node.folder.ID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		file := file
+		children = append(children, davNode{path: path.Join(node.path, file.Name), file: &file, parent: node.folder})
+	}
+
+	return children, nil
+}
+
+func (s *Server) davPropfind(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if !node.exists() {
+		writeError(w, http.StatusNotFound, "resource does not exist")
+		return
+	}
+
+	nodes := []davNode{node}
+	if node.isCollection() && r.Header.Get("Depth") != "0" {
+		children, err := s.davChildren(r.Context(), username, node)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		nodes = append(nodes, children...)
+	}
+
+	ms := davMultistatus{XMLNS: "DAV:"}
+	for _, n := range nodes {
+		ms.Responses = append(ms.Responses, newDAVResponse(n))
+	}
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(ms)
+}
+
+func (s *Server) davGet(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if node.isCollection() {
+		methodNotAllowed(w, "OPTIONS", "PROPFIND", "DELETE", "MOVE", "COPY", "LOCK", "UNLOCK")
+		return
+	}
+
+	if node.file == nil {
+		writeError(w, http.StatusNotFound, "file does not exist")
+		return
+	}
+
+	content, err := s.fileService.OpenContext(r.Context(), username, node.parent.ID, node.file.Name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", fmt.Sprint(node.file.Size))
+	w.Header().Set("ETag", davETag(*node.file))
+	w.Header().Set("Last-Modified", lastModified(*node.file).UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method != http.MethodHead {
+		io.Copy(w, content)
+	}
+}
+
+// davPut stores the request body as the file. An existing file gets a new revision, so nothing is lost.
+func (s *Server) davPut(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if node.isCollection() {
+		writeError(w, http.StatusMethodNotAllowed, "a folder cannot be written")
+		return
+	}
+
+	if node.parent == nil {
+		writeError(w, http.StatusConflict, "files can only be put in a folder")
+		return
+	}
+
+	if !s.limitBody(w, r) {
+		return
+	}
+
+	_, name := path.Split(node.path)
+	if node.file != nil {
+		if _, err := s.fileService.UploadVersionContext(r.Context(), username, node.parent.ID, node.file.Name, "", r.Body); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, err := s.fileService.UploadContext(r.Context(), username, node.parent.ID, name, "", r.Body); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) davDelete(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if !node.exists() {
+		writeError(w, http.StatusNotFound, "resource does not exist")
+		return
+	}
+
+	if err := s.davRemove(r.Context(), username, node); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// davRemove deletes the node, a collection goes together with everything below it.
+func (s *Server) davRemove(ctx context.Context, username string, node davNode) error {
+	if node.isRoot() {
+		return &services.Error{
+			Err:     services.ErrPermissionDenied,
+			Entity:  "folder",
+			ID:      "/",
+			Message: "permission denied: the root cannot be deleted",
+		}
+	}
+
+	if node.folder != nil {
+		return s.folderService.DeleteContext(ctx, node.folder.ID, username, true)
+	}
+
+	return s.fileService.DeleteContext(ctx, username, node.parent.ID, node.file.Name)
+}
+
+func (s *Server) davMkcol(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if r.ContentLength > 0 {
+		writeError(w, http.StatusUnsupportedMediaType, "request body is not supported")
+		return
+	}
+
+	if node.exists() {
+		methodNotAllowed(w, "OPTIONS", "PROPFIND", "DELETE", "MOVE", "COPY", "LOCK", "UNLOCK")
+		return
+	}
+
+	if dir, _ := path.Split(node.path); dir != "/" && node.parent == nil {
+		writeError(w, http.StatusConflict, "parent folder does not exist")
+		return
+	}
+
+	if _, err := s.folderService.CreateContext(r.Context(), node.path, username, ""); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) davMoveOrCopy(w http.ResponseWriter, r *http.Request, username string, node davNode) {
+	if !node.exists() || node.isRoot() {
+		writeError(w, http.StatusNotFound, "resource does not exist")
+		return
+	}
+
+	destination, err := url.Parse(r.Header.Get("Destination"))
+	if err != nil || !strings.HasPrefix(destination.Path, davPrefix+"/") {
+		writeError(w, http.StatusBadRequest, "destination should be under "+davPrefix)
+		return
+	}
+
+	dest, err := s.davLocate(r.Context(), username, strings.TrimPrefix(destination.Path, davPrefix))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			writeError(w, http.StatusConflict, "destination folder does not exist")
+			return
+		}
+
+		writeServiceError(w, err)
+		return
+	}
+
+	// Files need a folder to go to, folders may go to the root as well.
+	if dest.isRoot() || (dest.parent == nil && (node.file != nil || path.Dir(dest.path) != "/")) {
+		writeError(w, http.StatusConflict, "destination folder does not exist")
+		return
+	}
+
+	// Names resolve regardless of case, so the destination is the source itself when only the case differs,
+	// which a move takes as a plain rename.
+	if davSameNode(node, dest) {
+		if r.Method != "MOVE" || dest.path == node.path {
+			writeError(w, http.StatusForbidden, "destination is the source itself")
+			return
+		}
+
+		if err := s.davMove(r.Context(), username, node, dest.parent, path.Base(dest.path)); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if node.folder != nil {
+		below, err := s.davIsBelow(r.Context(), dest.parent, node.folder.ID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		if below {
+			writeError(w, http.StatusForbidden, "destination is below the source")
+			return
+		}
+	}
+
+	if !dest.exists() {
+		if err := s.davTransfer(r.Context(), r.Method, username, node, dest.parent, dest.path); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if r.Header.Get("Overwrite") == "F" {
+		writeError(w, http.StatusPreconditionFailed, "destination already exists")
+		return
+	}
+
+	// The source is moved or copied next to the destination under a temporary name first,
+	// so that the destination is only removed once the source made it there.
+	temp := path.Join(path.Dir(dest.path), davTempName(path.Base(dest.path)))
+	if err := s.davTransfer(r.Context(), r.Method, username, node, dest.parent, temp); err != nil {
+		if r.Method == "COPY" {
+			s.davDiscard(r.Context(), username, temp)
+		}
+
+		writeServiceError(w, err)
+		return
+	}
+
+	var removed bool
+	moved, err := s.davLocate(r.Context(), username, temp)
+	if err == nil {
+		err = s.davRemove(r.Context(), username, dest)
+		removed = err == nil
+	}
+	if err == nil {
+		err = s.davMove(r.Context(), username, moved, dest.parent, path.Base(dest.path))
+	}
+	if err != nil {
+		if r.Method == "MOVE" {
+			s.davMove(r.Context(), username, moved, node.parent, path.Base(node.path))
+		} else {
+			s.davDiscard(r.Context(), username, temp)
+		}
+
+		// The destination went to the trash, it is put back where it was.
+		if removed {
+			s.davRestore(r.Context(), username, dest)
+		}
+
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// davSameNode returns true if both nodes are the same folder or the same file, whatever the case of their paths.
+func davSameNode(a davNode, b davNode) bool {
+	if a.folder != nil && b.folder != nil {
+		return a.folder.ID == b.folder.ID
+	}
+
+	return a.file != nil && b.file != nil && a.file.ID == b.file.ID
+}
+
+// davIsBelow returns true if the folder with the given ID is the parent or one of the ancestors of parent.
+func (s *Server) davIsBelow(ctx context.Context, parent *models.Folder, id int) (bool, error) {
+	for parent != nil {
+		if parent.ID == id {
+			return true, nil
+		}
+
+		if parent.ParentID == 0 {
+			return false, nil
+		}
+
+		var err error
+		if parent, err = s.folderService.GetContext(ctx, parent.ParentID); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// davTempName returns a hidden name, unlikely to be taken, for the node on its way to the given name.
+func davTempName(name string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return "." + name + ".vfs-" + hex.EncodeToString(b)
+}
+
+// davTransfer moves or copies the node, as told by the method, to the path p under parent.
+func (s *Server) davTransfer(ctx context.Context, method string, username string, node davNode, parent *models.Folder, p string) error {
+	if method == "MOVE" {
+		return s.davMove(ctx, username, node, parent, path.Base(p))
+	}
+
+	return s.davCopy(ctx, username, node, p)
+}
+
+// davDiscard removes whatever a failed copy left at the path.
+func (s *Server) davDiscard(ctx context.Context, username string, p string) {
+	if node, err := s.davLocate(ctx, username, p); err == nil && node.exists() {
+		s.davRemove(ctx, username, node)
+	}
+}
+
+// davRestore puts the removed node back from the trash of the user.
+func (s *Server) davRestore(ctx context.Context, username string, node davNode) error {
+	items, err := s.trashService.ListContext(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	// The node was removed last, so its item is looked for from the newest one.
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if node.folder != nil && len(item.Folders) > 0 && item.Folders[0].ID == node.folder.ID ||
+			node.file != nil && len(item.Files) > 0 && item.Files[0].ID == node.file.ID {
+			return s.trashService.RestoreContext(ctx, item.ID, username)
+		}
+	}
+
+	return &services.Error{
+		Err:     services.ErrNotFound,
+		Entity:  "item",
+		ID:      node.path,
+		Message: "item does not exist in the trash",
+	}
+}
+
+// davMove moves the node under parent with the given name, the root of the user if parent is nil.
+func (s *Server) davMove(ctx context.Context, username string, node davNode, parent *models.Folder, name string) error {
+	var parentID int
+	if parent != nil {
+		parentID = parent.ID
+	}
+
+	if node.folder != nil {
+		return s.folderService.MoveContext(ctx, node.folder.ID, parentID, name, username)
+	}
+
+	_, err := s.fileService.MoveContext(ctx, username, node.parent.ID, node.file.Name, parentID, name)
+	return err
+}
+
+// davCopy copies the node to the path, a collection is copied together with everything below it.
+func (s *Server) davCopy(ctx context.Context, username string, node davNode, p string) error {
+	if node.file != nil {
+		dest, err := s.davLocate(ctx, username, p)
+		if err != nil {
+			return err
+		}
+
+		content, err := s.fileService.OpenContext(ctx, username, node.parent.ID, node.file.Name)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		_, name := path.Split(p)
+		_, err = s.fileService.UploadContext(ctx, username, dest.parent.ID, name, node.file.Desc, content)
+		return err
+	}
+
+	if _, err := s.folderService.CreateContext(ctx, p, username, node.folder.Description); err != nil {
+		return err
+	}
+
+	children, err := s.davChildren(ctx, username, node)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		_, name := path.Split(child.path)
+		if err := s.davCopy(ctx, username, child, path.Join(p, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// davLock grants an exclusive write lock. Locks are not enforced, which is why only class 1 is advertised,
+// they are only handed out because some clients refuse to write without taking one first.
+func (s *Server) davLock(w http.ResponseWriter, r *http.Request, node davNode) {
+	io.Copy(ioutil.Discard, r.Body)
+
+	token := r.Header.Get("If")
+	token = strings.Trim(token, "()<> ")
+	if token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		token = "opaquelocktoken:" + hex.EncodeToString(b)
+	}
+
+	prop := davLockProp{XMLNS: "DAV:"}
+	prop.LockDiscovery.ActiveLock = davActiveLock{
+		LockType:  davLockType{Write: &struct{}{}},
+		LockScope: davLockScope{Exclusive: &struct{}{}},
+		Depth:     "infinity",
+		Timeout:   "Second-3600",
+		LockToken: davHref{Href: token},
+		LockRoot:  davHref{Href: davHrefOf(node.path)},
+	}
+
+	w.Header().Set("Lock-Token", "<"+token+">")
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(prop)
+}
+
+// davMultistatus is the body of a PROPFIND response.
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	XMLNS     string        `xml:"xmlns:D,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href     string      `xml:"D:href"`
+	Propstat davPropstat `xml:"D:propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davProp struct {
+	DisplayName   string          `xml:"D:displayname"`
+	ResourceType  davResourceType `xml:"D:resourcetype"`
+	ContentLength *int64          `xml:"D:getcontentlength,omitempty"`
+	ContentType   string          `xml:"D:getcontenttype,omitempty"`
+	ETag          string          `xml:"D:getetag,omitempty"`
+	CreationDate  string          `xml:"D:creationdate,omitempty"`
+	LastModified  string          `xml:"D:getlastmodified,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+}
+
+// davLockProp is the body of a LOCK response.
+type davLockProp struct {
+	XMLName       xml.Name `xml:"D:prop"`
+	XMLNS         string   `xml:"xmlns:D,attr"`
+	LockDiscovery struct {
+		ActiveLock davActiveLock `xml:"D:activelock"`
+	} `xml:"D:lockdiscovery"`
+}
+
+type davActiveLock struct {
+	LockType  davLockType  `xml:"D:locktype"`
+	LockScope davLockScope `xml:"D:lockscope"`
+	Depth     string       `xml:"D:depth"`
+	Timeout   string       `xml:"D:timeout"`
+	LockToken davHref      `xml:"D:locktoken"`
+	LockRoot  davHref      `xml:"D:lockroot"`
+}
+
+type davLockType struct {
+	Write *struct{} `xml:"D:write,omitempty"`
+}
+
+type davLockScope struct {
+	Exclusive *struct{} `xml:"D:exclusive,omitempty"`
+}
+
+type davHref struct {
+	Href string `xml:"D:href"`
+}
+
+func newDAVResponse(node davNode) davResponse {
+	href := davHrefOf(node.path)
+
+	prop := davProp{DisplayName: path.Base(node.path)}
+	switch {
+	case node.file != nil:
+		size := node.file.Size
+		prop.ContentLength = &size
+		prop.ContentType = "application/octet-stream"
+		prop.ETag = davETag(*node.file)
+		prop.CreationDate = node.file.CreatedAt.UTC().Format(time.RFC3339)
+		prop.LastModified = lastModified(*node.file).UTC().Format(http.TimeFormat)
+	case node.folder != nil:
+		prop.ResourceType.Collection = &struct{}{}
+		prop.CreationDate = node.folder.CreatedAt.UTC().Format(time.RFC3339)
+		prop.LastModified = node.folder.CreatedAt.UTC().Format(http.TimeFormat)
+		href += "/"
+	default:
+		prop.DisplayName = ""
+		prop.ResourceType.Collection = &struct{}{}
+		href += "/"
+	}
+
+	return davResponse{
+		Href: href,
+		Propstat: davPropstat{
+			Prop:   prop,
+			Status: "HTTP/1.1 200 OK",
+		},
+	}
+}
+
+// davHrefOf returns the escaped URL path of the node at p.
+func davHrefOf(p string) string {
+	return (&url.URL{Path: strings.TrimSuffix(davPrefix+p, "/")}).EscapedPath()
+}
+
+func davETag(file models.File) string {
+	return fmt.Sprintf("%q", file.Checksum)
+}
+
+// lastModified returns the time the current revision of the file was uploaded.
+func lastModified(file models.File) time.Time {
+	if n := len(file.Versions); n > 0 {
+		return file.Versions[n-1].CreatedAt
+	}
+
+	return file.CreatedAt
+}
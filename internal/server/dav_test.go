@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+func TestServer_ServeDAV(t *testing.T) {
+	type request struct {
+		method  string
+		path    string
+		headers map[string]string
+		body    string
+	}
+	tests := []struct {
+		name       string
+		requests   []request
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{
+			name: "01. it should list the folders at the root of the user.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PROPFIND", path: "/dav/", headers: map[string]string{"Depth": "1"}},
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   "<D:href>/dav/Work/</D:href>",
+		},
+		{
+			name: "02. it should put and get a file.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "GET", path: "/dav/Work/1.tc"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name: "03. it should keep the previous content as a revision when a file is put again.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "world"},
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "04. it should return conflict when the parent collection does not exist.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work/Reports"},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "05. it should move a file into another collection.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "MKCOL", path: "/dav/Work/Reports"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "MOVE", path: "/dav/Work/1.tc", headers: map[string]string{"Destination": "/dav/Work/Reports/2.tc"}},
+				{method: "GET", path: "/dav/Work/Reports/2.tc"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name: "06. it should copy a collection together with its files.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "COPY", path: "/dav/Work", headers: map[string]string{"Destination": "/dav/Backup"}},
+				{method: "GET", path: "/dav/Backup/1.tc"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name: "07. it should not overwrite the destination when told not to.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "MKCOL", path: "/dav/Backup"},
+				{method: "COPY", path: "/dav/Work", headers: map[string]string{"Destination": "/dav/Backup", "Overwrite": "F"}},
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name: "08. it should delete a collection together with its files.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "DELETE", path: "/dav/Work"},
+				{method: "PROPFIND", path: "/dav/Work", headers: map[string]string{"Depth": "0"}},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "09. it should hand out a lock token.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "LOCK", path: "/dav/Work"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "opaquelocktoken:",
+		},
+		{
+			name: "10. it should rename a file when only the case of its name changes.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "MOVE", path: "/dav/Work/1.tc", headers: map[string]string{"Destination": "/dav/Work/1.TC"}},
+				{method: "PROPFIND", path: "/dav/Work", headers: map[string]string{"Depth": "1"}},
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   "<D:href>/dav/Work/1.TC</D:href>",
+		},
+		{
+			name: "11. it should refuse to move a collection below itself whatever the case.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "MKCOL", path: "/dav/Work/Reports"},
+				{method: "MOVE", path: "/dav/Work", headers: map[string]string{"Destination": "/dav/work/reports/Work"}},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "12. it should replace the destination once the source is moved.",
+			requests: []request{
+				{method: "MKCOL", path: "/dav/Work"},
+				{method: "PUT", path: "/dav/Work/1.tc", body: "hello"},
+				{method: "PUT", path: "/dav/Work/2.tc", body: "world"},
+				{method: "MOVE", path: "/dav/Work/1.tc", headers: map[string]string{"Destination": "/dav/Work/2.tc"}},
+				{method: "GET", path: "/dav/Work/2.tc"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name: "13. it should advertise class 1 only, since locks are not enforced.",
+			requests: []request{
+				{method: "OPTIONS", path: "/dav/"},
+			},
+			wantStatus: http.StatusOK,
+			wantHeader: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &services.Factory{}
+			factory.GetUserService().Register("luke", "secret")
+
+			ts := httptest.NewServer(New(factory))
+			defer ts.Close()
+
+			var resp *http.Response
+			for i, req := range tt.requests {
+				r, err := http.NewRequest(req.method, ts.URL+req.path, strings.NewReader(req.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.SetBasicAuth("luke", "secret")
+				for k, v := range req.headers {
+					if k == "Destination" {
+						v = ts.URL + v
+					}
+					r.Header.Set(k, v)
+				}
+
+				if resp, err = ts.Client().Do(r); err != nil {
+					t.Fatal(err)
+				}
+				if i < len(tt.requests)-1 {
+					resp.Body.Close()
+				}
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Server.ServeHTTP() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantHeader != "" && resp.Header.Get("DAV") != tt.wantHeader {
+				t.Errorf("Server.ServeHTTP() DAV header = %q, want %q", resp.Header.Get("DAV"), tt.wantHeader)
+			}
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("Server.ServeHTTP() body = %s, want it to contain %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// failingMoves is a file service failing to move files to the given name.
+type failingMoves struct {
+	services.FileService
+	name string
+}
+
+func (f failingMoves) MoveContext(ctx context.Context, movedBy string, folderID int, filename string, destFolderID int, destName string) (*models.File, error) {
+	if destName == f.name {
+		return nil, errors.New("disk failure")
+	}
+
+	return f.FileService.MoveContext(ctx, movedBy, folderID, filename, destFolderID, destName)
+}
+
+func TestServer_ServeDAV_FailingOverwrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{
+			name:   "01. it should put the destination back when the moved file cannot take its place.",
+			method: "MOVE",
+		},
+		{
+			name:   "02. it should put the destination back when the copied file cannot take its place.",
+			method: "COPY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &services.Factory{}
+			factory.GetUserService().Register("luke", "")
+			work, _ := factory.GetFolderService().Create("Work", "luke", "")
+			factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader("source"))
+			factory.GetFileService().Upload("luke", work.ID, "2.tc", "", strings.NewReader("destination"))
+
+			handler := New(factory)
+			handler.fileService = failingMoves{FileService: handler.fileService, name: "2.tc"}
+			ts := httptest.NewServer(handler)
+			defer ts.Close()
+
+			r, _ := http.NewRequest(tt.method, ts.URL+"/dav/Work/1.tc", nil)
+			r.SetBasicAuth("luke", "")
+			r.Header.Set("Destination", ts.URL+"/dav/Work/2.tc")
+			resp, err := ts.Client().Do(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Errorf("Server.ServeHTTP() status = %v, want %v", resp.StatusCode, http.StatusInternalServerError)
+			}
+
+			files, _ := factory.GetFileService().GetAll("luke", work.ID, "sort_name", "asc")
+			if len(files) != 2 || files[0].Name != "1.tc" || files[1].Name != "2.tc" {
+				t.Errorf("FileService.GetAll() = %v, want 1.tc and 2.tc back in place", files)
+			}
+
+			content, err := factory.GetFileService().Open("luke", work.ID, "2.tc")
+			if err != nil {
+				t.Fatalf("FileService.Open() error = %v", err)
+			}
+			defer content.Close()
+
+			if got, _ := ioutil.ReadAll(content); string(got) != "destination" {
+				t.Errorf("FileService.Open() = %q, want %q", got, "destination")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"time"
+	"virtual-file-system/internal/models"
+)
+
+// fileResponse is the JSON representation of a file.
+type fileResponse struct {
+	ID          int       `json:"id"`
+	FolderID    int       `json:"folder_id"`
+	Name        string    `json:"name"`
+	Ext         string    `json:"ext"`
+	Description string    `json:"description"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	Version     int       `json:"version"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newFileResponse(file models.File) fileResponse {
+	return fileResponse{
+		ID:          file.ID,
+		FolderID:    file.FolderID,
+		Name:        file.Name,
+		Ext:         file.Ext,
+		Description: file.Desc,
+		Size:        file.Size,
+		Checksum:    file.Checksum,
+		Version:     file.Version,
+		CreatedBy:   file.CreatedBy,
+		CreatedAt:   file.CreatedAt,
+	}
+}
+
+func (s *Server) serveFiles(w http.ResponseWriter, r *http.Request, folderSegment string, segments []string) {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	folder, ok := s.readableFolder(w, r, username, folderSegment)
+	if !ok {
+		return
+	}
+
+	if len(segments) == 0 || segments[0] == "" {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+
+		query := r.URL.Query()
+		files, err := s.fileService.GetAllContext(r.Context(), username, folder.ID, query.Get("sort"), query.Get("order"))
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		resp := make([]fileResponse, 0, len(files))
+		for _, file := range files {
+			resp = append(resp, newFileResponse(file))
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	filename := segments[0]
+	description := r.URL.Query().Get("description")
+
+	if (r.Method == http.MethodPost || r.Method == http.MethodPut) && !s.limitBody(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		content, err := s.fileService.OpenContext(r.Context(), username, folder.ID, filename)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.Copy(w, content)
+	case http.MethodPost:
+		file, err := s.fileService.UploadContext(r.Context(), username, folder.ID, filename, description, r.Body)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, newFileResponse(*file))
+	case http.MethodPut:
+		file, err := s.fileService.UploadVersionContext(r.Context(), username, folder.ID, filename, description, r.Body)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newFileResponse(*file))
+	case http.MethodDelete:
+		if err := s.fileService.DeleteContext(r.Context(), username, folder.ID, filename); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete)
+	}
+}
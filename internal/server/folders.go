@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+// folderRequest is the body of a folder creation or rename.
+type folderRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// folderResponse is the JSON representation of a folder.
+type folderResponse struct {
+	ID          int       `json:"id"`
+	ParentID    int       `json:"parent_id,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	Shared      bool      `json:"shared"`
+}
+
+// newFolderResponse returns the folder as seen by the user, for whom it is shared unless they own it.
+func (s *Server) newFolderResponse(folder models.Folder, username string) folderResponse {
+	return folderResponse{
+		ID:          folder.ID,
+		ParentID:    folder.ParentID,
+		Name:        folder.Name,
+		Description: folder.Description,
+		CreatedBy:   folder.CreatedBy,
+		CreatedAt:   folder.CreatedAt,
+		Shared:      s.authorizer.FolderPermission(username, folder) < services.PermissionOwner,
+	}
+}
+
+func (s *Server) serveFolders(w http.ResponseWriter, r *http.Request, segments []string) {
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if len(segments) == 0 || segments[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			folders, err := s.folderService.GetAllContext(r.Context(), username, query.Get("sort"), query.Get("order"))
+			if err != nil {
+				writeServiceError(w, err)
+				return
+			}
+
+			resp := make([]folderResponse, 0, len(folders))
+			for _, folder := range folders {
+				resp = append(resp, s.newFolderResponse(folder, username))
+			}
+			writeJSON(w, http.StatusOK, resp)
+		case http.MethodPost:
+			var req folderRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+
+			folder, err := s.folderService.CreateContext(r.Context(), req.Name, username, req.Description)
+			if err != nil {
+				writeServiceError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, s.newFolderResponse(*folder, username))
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	folder, ok := s.readableFolder(w, r, username, segments[0])
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.newFolderResponse(*folder, username))
+	case http.MethodPatch:
+		var req folderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		if err := s.folderService.RenameContext(r.Context(), folder.ID, req.Name, username); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		folder.Name = req.Name
+		writeJSON(w, http.StatusOK, s.newFolderResponse(*folder, username))
+	case http.MethodDelete:
+		recursive, _ := strconv.ParseBool(r.URL.Query().Get("recursive"))
+		if err := s.folderService.DeleteContext(r.Context(), folder.ID, username, recursive); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// readableFolder returns the folder with the ID given in the path, provided the user can read it.
+// It writes the error response and returns false otherwise.
+func (s *Server) readableFolder(w http.ResponseWriter, r *http.Request, username string, segment string) (*models.Folder, bool) {
+	if _, err := strconv.Atoi(segment); err != nil {
+		writeError(w, http.StatusNotFound, "folder does not exist")
+		return nil, false
+	}
+
+	folder, err := s.folderService.ResolveContext(r.Context(), username, segment)
+	if err != nil {
+		writeServiceError(w, err)
+		return nil, false
+	}
+
+	return folder, true
+}
@@ -0,0 +1,188 @@
+// Package server exposes the virtual file system as a JSON REST API over HTTP.
+//
+// Requests are authenticated with HTTP basic authentication, users registered without a password
+// authenticate with an empty one. The resources are
+//
+//	POST   /users                            register a user: {"name": "...", "password": "..."}
+//	GET    /users/{name}                     check a user exists
+//	PATCH  /users/{name}                     change the password of the authenticated user: {"password": "..."}
+//	DELETE /users/{name}                     delete the authenticated user, once their folders are deleted
+//	GET    /folders?sort=&order=             list the folders the user can read
+//	POST   /folders                          create a folder: {"name": "/work/reports", "description": "..."}
+//	GET    /folders/{id}                     get a folder
+//	PATCH  /folders/{id}                     rename a folder: {"name": "..."}
+//	DELETE /folders/{id}?recursive=true      delete a folder
+//	GET    /folders/{id}/files?sort=&order=  list the files under a folder
+//	POST   /folders/{id}/files/{name}        upload the request body as a new file, ?description= is optional
+//	PUT    /folders/{id}/files/{name}        upload the request body as a new revision of the file
+//	GET    /folders/{id}/files/{name}        download the file content
+//	DELETE /folders/{id}/files/{name}        delete a file
+//
+// Uploaded contents are held in memory, so request bodies beyond MaxUploadSize are refused.
+// The folders and files of the user are also served over WebDAV under /dav, see serveDAV.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"virtual-file-system/internal/services"
+)
+
+// Server handles the REST API requests with the services of the factory,
+// so it shares the behavior and validation of the command line.
+type Server struct {
+	userService   services.UserService
+	folderService services.FolderService
+	fileService   services.FileService
+	trashService  services.TrashService
+	authorizer    services.Authorizer
+
+	// maxUploadSize is the size in bytes of the largest content accepted.
+	maxUploadSize int64
+}
+
+// MaxUploadSize is the size in bytes of the largest content a request may upload.
+const MaxUploadSize = 64 << 20
+
+// New returns a Server backed by the services of the factory.
+func New(factory *services.Factory) *Server {
+	return &Server{
+		userService:   factory.GetUserService(),
+		folderService: factory.GetFolderService(),
+		fileService:   factory.GetFileService(),
+		trashService:  factory.GetTrashService(),
+		authorizer:    factory.GetAuthorizer(),
+		maxUploadSize: MaxUploadSize,
+	}
+}
+
+// ServeHTTP routes the request to the handler of the resource.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case segments[0] == strings.TrimPrefix(davPrefix, "/"):
+		s.serveDAV(w, r)
+	case segments[0] == "users" && len(segments) <= 2:
+		s.serveUsers(w, r, segments[1:])
+	case segments[0] == "folders" && len(segments) <= 2:
+		s.serveFolders(w, r, segments[1:])
+	case segments[0] == "folders" && len(segments) <= 4 && segments[2] == "files":
+		s.serveFiles(w, r, segments[1], segments[3:])
+	default:
+		writeError(w, http.StatusNotFound, "resource does not exist")
+	}
+}
+
+// authenticate returns the user the request is made by.
+// It writes an unauthorized response and returns false if the credentials are missing or wrong.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="vfs"`)
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return "", false
+	}
+
+	if err := s.userService.AuthenticateContext(r.Context(), username, password); err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="vfs"`)
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return "", false
+	}
+
+	return username, true
+}
+
+// limitBody caps the request body at the largest upload accepted.
+// It writes a request entity too large response and returns false if the body is known to be larger.
+func (s *Server) limitBody(w http.ResponseWriter, r *http.Request) bool {
+	if r.ContentLength > s.maxUploadSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return false
+	}
+
+	body := &countingBody{ReadCloser: r.Body}
+	r.Body = cappedBody{ReadCloser: http.MaxBytesReader(w, body, s.maxUploadSize), body: body, limit: s.maxUploadSize}
+
+	return true
+}
+
+// errBodyTooLarge is the error read from a body going beyond the largest upload accepted.
+var errBodyTooLarge = errors.New("request body too large")
+
+// countingBody counts the bytes read from the request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+
+	return n, err
+}
+
+// cappedBody is a request body capped by http.MaxBytesReader, whose error has no type of its own before Go 1.19.
+// It fails with errBodyTooLarge instead once more than limit bytes were read from the body.
+type cappedBody struct {
+	io.ReadCloser
+	body  *countingBody
+	limit int64
+}
+
+func (b cappedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.body.n > b.limit {
+		err = errBodyTooLarge
+	}
+
+	return n, err
+}
+
+// errorResponse is the body of every failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeServiceError writes the error returned by a service with the status matching its kind.
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrPermissionDenied):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyExists), errors.Is(err, services.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrUnauthenticated):
+		status = http.StatusUnauthorized
+	case errors.Is(err, errBodyTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// The client gave up or the request timed out before the operation completed.
+		status = http.StatusServiceUnavailable
+	}
+
+	writeError(w, status, err.Error())
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
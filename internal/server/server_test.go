@@ -0,0 +1,274 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+func TestServer_ServeHTTP(t *testing.T) {
+	type request struct {
+		method   string
+		path     string
+		username string
+		password string
+		body     string
+		chunked  bool
+	}
+	tests := []struct {
+		name       string
+		requests   []request
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "01. it should create a folder for the authenticated user.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"name":"Work"`,
+		},
+		{
+			name: "02. it should reject a wrong password.",
+			requests: []request{
+				{method: "GET", path: "/folders", username: "luke", password: "wrong"},
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "03. it should upload and download the file content.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello"},
+				{method: "GET", path: "/folders/1001/files/1.tc", username: "luke", password: "secret"},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "hello",
+		},
+		{
+			name: "04. it should return conflict if the file already exists.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret"},
+				{method: "POST", path: "/folders/1001/files/1.TC", username: "luke", password: "secret"},
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "05. it should return forbidden if the user cannot write the folder.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "PATCH", path: "/folders/1001", username: "mark", body: `{"name":"Temp"}`},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "06. it should return not found for unknown folders.",
+			requests: []request{
+				{method: "GET", path: "/folders/9999", username: "luke", password: "secret"},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "07. it should refuse a content larger than the upload limit.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello world, hello world"},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "08. it should refuse a content of unknown length once it goes past the upload limit.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello world, hello world", chunked: true},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "request body too large",
+		},
+		{
+			name: "09. it should refuse a new revision of unknown length once it goes past the upload limit.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello"},
+				{method: "PUT", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello world, hello world", chunked: true},
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   "request body too large",
+		},
+		{
+			name: "10. it should accept a content of unknown length up to the upload limit.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "POST", path: "/folders/1001/files/1.tc", username: "luke", password: "secret", body: "hello world, hel", chunked: true},
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "11. it should let the user change their password.",
+			requests: []request{
+				{method: "PATCH", path: "/users/luke", username: "luke", password: "secret", body: `{"password":"changed"}`},
+				{method: "GET", path: "/folders", username: "luke", password: "changed"},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "12. it should return forbidden if the user changes the password of another user.",
+			requests: []request{
+				{method: "PATCH", path: "/users/luke", username: "mark", body: `{"password":"changed"}`},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "13. it should let the user delete their account.",
+			requests: []request{
+				{method: "DELETE", path: "/users/mark", username: "mark"},
+				{method: "GET", path: "/folders", username: "mark"},
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "14. it should return conflict if the user deletes their account while owning folders.",
+			requests: []request{
+				{method: "POST", path: "/folders", username: "luke", password: "secret", body: `{"name":"Work"}`},
+				{method: "DELETE", path: "/users/luke", username: "luke", password: "secret"},
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &services.Factory{}
+			factory.GetUserService().Register("luke", "secret")
+			factory.GetUserService().Register("mark", "")
+			handler := New(factory)
+			handler.maxUploadSize = 16
+
+			var rec *httptest.ResponseRecorder
+			for _, req := range tt.requests {
+				var body io.Reader = strings.NewReader(req.body)
+				if req.chunked {
+					body = ioutil.NopCloser(body)
+				}
+				r := httptest.NewRequest(req.method, req.path, body)
+				r.SetBasicAuth(req.username, req.password)
+
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Server.ServeHTTP() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+
+			body, _ := ioutil.ReadAll(rec.Body)
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("Server.ServeHTTP() body = %s, want it to contain %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_Concurrent(t *testing.T) {
+	factory := &services.Factory{}
+	factory.GetUserService().Register("luke", "")
+	handler := New(factory)
+
+	serve := func(method string, path string, body string) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.SetBasicAuth("luke", "")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		return rec.Code
+	}
+
+	var wg sync.WaitGroup
+	created := make(chan int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			created <- serve("POST", "/folders", `{"name":"Work"}`)
+			serve("POST", fmt.Sprintf("/folders/1001/files/%d.tc", i), "hello")
+			serve("GET", "/folders/1001/files", "")
+		}(i)
+	}
+	wg.Wait()
+	close(created)
+
+	succeeded := 0
+	for code := range created {
+		if code == http.StatusCreated {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("created the folder %d times, want 1", succeeded)
+	}
+
+	files, _ := factory.GetFileService().GetAll("luke", 1001, "", "")
+	if len(files) != 20 {
+		t.Errorf("uploaded %d files, want 20", len(files))
+	}
+}
+
+func TestServer_ServeHTTP_SharedFolders(t *testing.T) {
+	factory := &services.Factory{}
+	factory.GetUserService().Register("luke", "")
+	factory.GetUserService().Register("mark", "")
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+	factory.GetShareService().Share(work.ID, "mark", models.AccessReadWrite, "luke")
+	factory.GetFolderService().Create(fmt.Sprintf("%d/Reports", work.ID), "mark", "")
+	handler := New(factory)
+
+	tests := []struct {
+		name       string
+		username   string
+		wantShared map[string]bool
+	}{
+		{
+			name:       "01. it should not mark the folders created by a grantee under a folder the user owns as shared.",
+			username:   "luke",
+			wantShared: map[string]bool{"Work": false, "Reports": false},
+		},
+		{
+			name:       "02. it should mark the folder shared with the grantee as shared, but not the folders the grantee created.",
+			username:   "mark",
+			wantShared: map[string]bool{"Work": true, "Reports": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/folders", nil)
+			r.SetBasicAuth(tt.username, "")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			var folders []folderResponse
+			if err := json.NewDecoder(rec.Body).Decode(&folders); err != nil {
+				t.Fatalf("Server.ServeHTTP() body is not a folder list: %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, folder := range folders {
+				got[folder.Name] = folder.Shared
+			}
+			if !reflect.DeepEqual(got, tt.wantShared) {
+				t.Errorf("Server.ServeHTTP() shared = %v, want %v", got, tt.wantShared)
+			}
+		})
+	}
+}
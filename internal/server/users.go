@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// userRequest is the body of a registration, or of a password change which leaves the name out.
+type userRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// userResponse is the JSON representation of a user.
+type userResponse struct {
+	Name string `json:"name"`
+}
+
+func (s *Server) serveUsers(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		if err := s.userService.RegisterContext(r.Context(), req.Name, req.Password); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, userResponse{Name: req.Name})
+		return
+	}
+
+	username, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !s.userService.Exists(segments[0]) {
+			writeError(w, http.StatusNotFound, "user does not exist")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, userResponse{Name: segments[0]})
+	case http.MethodPatch:
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		if err := s.userService.ChangePasswordContext(r.Context(), segments[0], req.Password, username); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, userResponse{Name: segments[0]})
+	case http.MethodDelete:
+		if err := s.userService.DeleteContext(r.Context(), segments[0], username); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+func TestAuthorizerImpl_FolderPermission(t *testing.T) {
+	folders := map[int]*models.Folder{
+		1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+		1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+		1003: {ID: 1003, Name: "Testing", CreatedBy: "Mark"},
+	}
+	tests := []struct {
+		name     string
+		username string
+		folderID int
+		want     Permission
+	}{
+		{name: "01. it should grant owner to the creator.", username: "Luke", folderID: 1001, want: PermissionOwner},
+		{name: "02. it should compare the creator case insensitively.", username: "luke", folderID: 1001, want: PermissionOwner},
+		{name: "03. it should grant owner on subfolders to the owner of an ancestor.", username: "Luke", folderID: 1002, want: PermissionOwner},
+		{name: "04. it should grant nothing to other users.", username: "Mark", folderID: 1002, want: PermissionNone},
+		{name: "05. it should grant read to a read-only grantee.", username: "April", folderID: 1001, want: PermissionRead},
+		{name: "06. it should grant write on subfolders to a read-write grantee of an ancestor.", username: "Bob", folderID: 1002, want: PermissionWrite},
+	}
+	grants := map[string]models.Grant{
+		"1001:april": {FolderID: 1001, Grantee: "April", Access: models.AccessReadOnly},
+		"1001:bob":   {FolderID: 1001, Grantee: "Bob", Access: models.AccessReadWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := &AuthorizerImpl{store: &MemoryStore{folders: folders, grants: grants}}
+			if got := authorizer.FolderPermission(tt.username, *folders[tt.folderID]); got != tt.want {
+				t.Errorf("AuthorizerImpl.FolderPermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizerImpl_FilePermission(t *testing.T) {
+	folders := map[int]*models.Folder{
+		1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+	}
+	tests := []struct {
+		name     string
+		username string
+		file     models.File
+		want     Permission
+	}{
+		{name: "01. it should grant owner to the uploader who can write the folder.", username: "Mark", file: models.File{FolderID: 1001, CreatedBy: "Mark"}, want: PermissionOwner},
+		{name: "02. it should inherit the permission on the folder.", username: "Luke", file: models.File{FolderID: 1001, CreatedBy: "Mark"}, want: PermissionOwner},
+		{name: "03. it should grant nothing to other users.", username: "John", file: models.File{FolderID: 1001, CreatedBy: "Mark"}, want: PermissionNone},
+		{name: "04. it should grant only read to the uploader once the folder is shared read-only with them.", username: "April", file: models.File{FolderID: 1001, CreatedBy: "April"}, want: PermissionRead},
+		{name: "05. it should grant nothing to the uploader once the folder is no longer shared with them.", username: "Bob", file: models.File{FolderID: 1001, CreatedBy: "Bob"}, want: PermissionNone},
+	}
+	grants := map[string]models.Grant{
+		"1001:mark":  {FolderID: 1001, Grantee: "Mark", Access: models.AccessReadWrite},
+		"1001:april": {FolderID: 1001, Grantee: "April", Access: models.AccessReadOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := &AuthorizerImpl{store: &MemoryStore{folders: folders, grants: grants}}
+			if got := authorizer.FilePermission(tt.username, tt.file); got != tt.want {
+				t.Errorf("AuthorizerImpl.FilePermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
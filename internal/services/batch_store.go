@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"virtual-file-system/internal/models"
+)
+
+// batchStore collects the writes made through it as records instead of performing them,
+// so they can be applied all together. Reads and ID reservations go to the underlying store,
+// hence writes made earlier in the same batch are not visible to them.
+type batchStore struct {
+	Store
+	records []walRecord
+}
+
+// PutUser records storing the user under the given key.
+func (batch *batchStore) PutUser(key string, user models.User) error {
+	return batch.add(walRecord{Op: opPutUser, Key: key, User: &user})
+}
+
+// DeleteUser records removing the user stored under the given key.
+func (batch *batchStore) DeleteUser(key string) error {
+	return batch.add(walRecord{Op: opDeleteUser, Key: key})
+}
+
+// PutFolder records storing the folder under the given ID.
+func (batch *batchStore) PutFolder(id int, folder models.Folder) error {
+	return batch.add(walRecord{Op: opPutFolder, ID: id, Folder: &folder})
+}
+
+// DeleteFolder records removing the folder with given ID.
+func (batch *batchStore) DeleteFolder(id int) error {
+	return batch.add(walRecord{Op: opDeleteFolder, ID: id})
+}
+
+// PutFile records storing the file under the given ID.
+func (batch *batchStore) PutFile(id int, file models.File) error {
+	return batch.add(walRecord{Op: opPutFile, ID: id, File: &file})
+}
+
+// DeleteFile records removing the file with given ID.
+func (batch *batchStore) DeleteFile(id int) error {
+	return batch.add(walRecord{Op: opDeleteFile, ID: id})
+}
+
+// PutBlob records storing the content under the given checksum.
+func (batch *batchStore) PutBlob(checksum string, data []byte) error {
+	return batch.add(walRecord{Op: opPutBlob, Key: checksum, Data: data})
+}
+
+// DeleteBlob records removing the content with given checksum.
+func (batch *batchStore) DeleteBlob(checksum string) error {
+	return batch.add(walRecord{Op: opDeleteBlob, Key: checksum})
+}
+
+// PutGrant records storing the grant under the given key.
+func (batch *batchStore) PutGrant(key string, grant models.Grant) error {
+	return batch.add(walRecord{Op: opPutGrant, Key: key, Grant: &grant})
+}
+
+// DeleteGrant records removing the grant stored under the given key.
+func (batch *batchStore) DeleteGrant(key string) error {
+	return batch.add(walRecord{Op: opDeleteGrant, Key: key})
+}
+
+// PutGroup records storing the group under the given key.
+func (batch *batchStore) PutGroup(key string, group models.Group) error {
+	return batch.add(walRecord{Op: opPutGroup, Key: key, Group: &group})
+}
+
+// DeleteGroup records removing the group stored under the given key.
+func (batch *batchStore) DeleteGroup(key string) error {
+	return batch.add(walRecord{Op: opDeleteGroup, Key: key})
+}
+
+// PutTrash records storing the item under the given ID.
+func (batch *batchStore) PutTrash(id int, item models.TrashItem) error {
+	return batch.add(walRecord{Op: opPutTrash, ID: id, Trash: &item})
+}
+
+// DeleteTrash records removing the item with given ID.
+func (batch *batchStore) DeleteTrash(id int) error {
+	return batch.add(walRecord{Op: opDeleteTrash, ID: id})
+}
+
+// Batch joins the writes made by fn to the enclosing batch.
+func (batch *batchStore) Batch(fn func(Store) error) error {
+	return fn(batch)
+}
+
+func (batch *batchStore) add(record walRecord) error {
+	batch.records = append(batch.records, record)
+	return nil
+}
+
+// apply performs the logged operation against the store, a batch being applied all at once under the write lock.
+func (store *MemoryStore) apply(record walRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.applyLocked(record)
+}
+
+// applyLocked performs the logged operation, the caller holding the write lock.
+func (store *MemoryStore) applyLocked(record walRecord) error {
+	switch record.Op {
+	case opPutUser:
+		if store.users == nil {
+			store.users = make(map[string]models.User)
+		}
+		store.users[record.Key] = *record.User
+	case opDeleteUser:
+		delete(store.users, record.Key)
+	case opPutFolder:
+		if store.folders == nil {
+			store.folders = make(map[int]*models.Folder)
+		}
+		folder := *record.Folder
+		store.folders[record.ID] = &folder
+	case opDeleteFolder:
+		delete(store.folders, record.ID)
+	case opNextFolderID:
+		if record.ID >= store.nextFolderID {
+			store.nextFolderID = record.ID + 1
+		}
+	case opPutFile:
+		if store.files == nil {
+			store.files = make(map[int]models.File)
+		}
+		store.files[record.ID] = *record.File
+	case opDeleteFile:
+		delete(store.files, record.ID)
+	case opNextFileID:
+		if record.ID >= store.nextFileID {
+			store.nextFileID = record.ID + 1
+		}
+	case opPutBlob:
+		if store.blobs == nil {
+			store.blobs = make(map[string][]byte)
+		}
+		store.blobs[record.Key] = record.Data
+	case opDeleteBlob:
+		delete(store.blobs, record.Key)
+	case opPutGrant:
+		if store.grants == nil {
+			store.grants = make(map[string]models.Grant)
+		}
+		store.grants[record.Key] = *record.Grant
+	case opDeleteGrant:
+		delete(store.grants, record.Key)
+	case opPutGroup:
+		if store.groups == nil {
+			store.groups = make(map[string]models.Group)
+		}
+		store.groups[record.Key] = *record.Group
+	case opDeleteGroup:
+		delete(store.groups, record.Key)
+	case opPutTrash:
+		if store.trash == nil {
+			store.trash = make(map[int]models.TrashItem)
+		}
+		store.trash[record.ID] = *record.Trash
+	case opDeleteTrash:
+		delete(store.trash, record.ID)
+	case opNextTrashID:
+		if record.ID >= store.nextTrashID {
+			store.nextTrashID = record.ID + 1
+		}
+	case opBatch:
+		for _, r := range record.Records {
+			if err := store.applyLocked(r); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %q", errUnknownWALOp, record.Op)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"io"
+)
+
+// userContextKey is the key of the acting user in a context.
+type userContextKey struct{}
+
+// WithUser returns a copy of ctx carrying the acting user.
+// The context-aware methods of the services act on behalf of that user when they are given an empty user name.
+func WithUser(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, username)
+}
+
+// UserFromContext returns the acting user carried by ctx, if any.
+func UserFromContext(ctx context.Context) (string, bool) {
+	username, ok := ctx.Value(userContextKey{}).(string)
+
+	return username, ok && username != ""
+}
+
+// actingUser returns the user name if given, otherwise the acting user carried by ctx.
+func actingUser(ctx context.Context, username string) string {
+	if username != "" {
+		return username
+	}
+
+	username, _ = UserFromContext(ctx)
+
+	return username
+}
+
+// contextReader reads from r until ctx is done, so a large content stops being read once the caller gives up.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// cancelingReader cancels the context once its first chunk is read, as a client giving up halfway through an upload.
+type cancelingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (cr cancelingReader) Read(p []byte) (int, error) {
+	defer cr.cancel()
+
+	return cr.r.Read(p[:1])
+}
+
+func TestFactory_Context(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		op      func(factory *Factory, workID int) error
+		wantErr error
+		check   func(factory *Factory, workID int) error
+	}{
+		{
+			name: "01. it should act on behalf of the user carried by the context.",
+			op: func(factory *Factory, workID int) error {
+				_, err := factory.GetFolderService().CreateContext(WithUser(context.Background(), "luke"), "Home", "", "")
+				return err
+			},
+			check: func(factory *Factory, workID int) error {
+				_, err := factory.GetFolderService().Resolve("luke", "/Home")
+				return err
+			},
+		},
+		{
+			name: "02. it should prefer the given user over the one carried by the context.",
+			op: func(factory *Factory, workID int) error {
+				return factory.GetFolderService().RenameContext(WithUser(context.Background(), "luke"), workID, "Jobs", "mark")
+			},
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name: "03. it should not delete the folder once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				return factory.GetFolderService().DeleteContext(canceled, workID, "luke", true)
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				if trash, _ := factory.GetTrashService().List("luke"); len(trash) != 0 {
+					return errors.New("folder went to the trash")
+				}
+				_, err := factory.GetFolderService().Get(workID)
+				return err
+			},
+		},
+		{
+			name: "04. it should stop reading an upload once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				content := cancelingReader{r: strings.NewReader("a large content"), cancel: cancel}
+				_, err := factory.GetFileService().UploadContext(ctx, "luke", workID, "2.tc", "", content)
+				return err
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				if _, err := factory.GetFileService().ListVersions("luke", workID, "2.tc"); !errors.Is(err, ErrNotFound) {
+					return errors.New("file was stored")
+				}
+				return nil
+			},
+		},
+		{
+			name: "05. it should not store a new revision once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				_, err := factory.GetFileService().UploadVersionContext(canceled, "luke", workID, "1.tc", "", strings.NewReader("new"))
+				return err
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				versions, err := factory.GetFileService().ListVersions("luke", workID, "1.tc")
+				if err == nil && len(versions) != 1 {
+					return errors.New("revision was stored")
+				}
+				return err
+			},
+		},
+		{
+			name: "06. it should not register the user once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				return factory.GetUserService().RegisterContext(canceled, "john", "")
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				if factory.GetUserService().Exists("john") {
+					return errors.New("user was registered")
+				}
+				return nil
+			},
+		},
+		{
+			name: "07. it should not restore the item once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				factory.GetFileService().Delete("luke", workID, "1.tc")
+				items, _ := factory.GetTrashService().List("luke")
+				return factory.GetTrashService().RestoreContext(canceled, items[0].ID, "luke")
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				if items, _ := factory.GetTrashService().List("luke"); len(items) != 1 {
+					return errors.New("item was restored")
+				}
+				return nil
+			},
+		},
+		{
+			name: "08. it should not empty the trash once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				factory.GetFileService().Delete("luke", workID, "1.tc")
+				return factory.GetTrashService().EmptyContext(canceled, "luke")
+			},
+			wantErr: context.Canceled,
+			check: func(factory *Factory, workID int) error {
+				if items, _ := factory.GetTrashService().List("luke"); len(items) != 1 {
+					return errors.New("trash was emptied")
+				}
+				return nil
+			},
+		},
+		{
+			name: "09. it should list the trash of the user carried by the context.",
+			op: func(factory *Factory, workID int) error {
+				factory.GetFileService().Delete("luke", workID, "1.tc")
+				items, err := factory.GetTrashService().ListContext(WithUser(context.Background(), "luke"), "")
+				if err == nil && len(items) != 1 {
+					return errors.New("item is not listed")
+				}
+				return err
+			},
+		},
+		{
+			name: "10. it should not look the users and folders up once the context is done.",
+			op: func(factory *Factory, workID int) error {
+				if _, err := factory.GetUserService().ExistsContext(canceled, "luke"); err == nil {
+					return errors.New("user was looked up")
+				}
+				if _, err := factory.GetUserService().NamesContext(canceled); err == nil {
+					return errors.New("users were listed")
+				}
+				if _, err := factory.GetUserService().HasPasswordContext(canceled, "luke"); err == nil {
+					return errors.New("password was looked up")
+				}
+				_, err := factory.GetFolderService().ExistsContext(canceled, workID)
+				return err
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			factory.GetUserService().Register("luke", "")
+			factory.GetUserService().Register("mark", "")
+			work, _ := factory.GetFolderService().Create("Work", "luke", "")
+			factory.GetFolderService().Create("/Work/Reports", "luke", "")
+			factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader("hello"))
+
+			err := tt.op(factory, work.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.check != nil {
+				if err := tt.check(factory, work.ID); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestUserFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "01. it should return the user carried by the context.",
+			ctx:    WithUser(context.Background(), "luke"),
+			want:   "luke",
+			wantOk: true,
+		},
+		{
+			name: "02. it should return false when the context carries no user.",
+			ctx:  context.Background(),
+		},
+		{
+			name: "03. it should return false when the context carries an empty user.",
+			ctx:  WithUser(context.Background(), ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := UserFromContext(tt.ctx)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("UserFromContext() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
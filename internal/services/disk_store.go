@@ -0,0 +1,467 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"virtual-file-system/internal/models"
+)
+
+const (
+	snapshotFileName = "snapshot.json"
+	walFileName      = "wal.log"
+
+	// DefaultCheckpointInterval is the number of logged operations between two snapshots.
+	DefaultCheckpointInterval = 1000
+)
+
+// DiskStore is the Store implementation persisted under a directory.
+// Every mutating operation is appended to a write-ahead log before it is applied in memory,
+// and the log is folded into a snapshot every so often to keep the restart cost bounded.
+// It is safe for concurrent use, writes being logged one at a time while reads are served from memory.
+type DiskStore struct {
+	// mu serializes the writes to the log.
+	mu sync.Mutex
+
+	memory             *MemoryStore
+	dir                string
+	log                *wal
+	pending            int
+	checkpointInterval int
+
+	// checkpointErr is the error of the latest checkpoint taken on the way of a write, nil once one succeeds.
+	checkpointErr error
+}
+
+// snapshot is the on-disk image of the whole store.
+type snapshot struct {
+	Users        map[string]models.User   `json:"users"`
+	Folders      map[int]models.Folder    `json:"folders"`
+	Files        map[int]models.File      `json:"files"`
+	Blobs        map[string][]byte        `json:"blobs"`
+	Grants       map[string]models.Grant  `json:"grants"`
+	Groups       map[string]models.Group  `json:"groups"`
+	Trash        map[int]models.TrashItem `json:"trash"`
+	NextFolderID int                      `json:"next_folder_id"`
+	NextFileID   int                      `json:"next_file_id"`
+	NextTrashID  int                      `json:"next_trash_id"`
+}
+
+// OpenDiskStore loads the store persisted under dir, creating the directory if needed.
+// The latest snapshot is loaded first and the write-ahead log is replayed on top of it.
+// A checkpoint is taken after every checkpointInterval operations, or after
+// DefaultCheckpointInterval operations if it is not positive.
+func OpenDiskStore(dir string, checkpointInterval int) (*DiskStore, error) {
+	if checkpointInterval <= 0 {
+		checkpointInterval = DefaultCheckpointInterval
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	store := &DiskStore{
+		memory:             NewMemoryStore(),
+		dir:                dir,
+		checkpointInterval: checkpointInterval,
+	}
+
+	if err := store.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	log, err := openWAL(filepath.Join(dir, walFileName), func(record walRecord) error {
+		store.pending++
+		return store.apply(record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	store.log = log
+	return store, nil
+}
+
+// Close takes a final checkpoint and releases the log file.
+func (store *DiskStore) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.checkpoint(); err != nil {
+		store.log.close()
+		return err
+	}
+
+	return store.log.close()
+}
+
+// Checkpoint writes the current state as a new snapshot and empties the write-ahead log.
+// The snapshot is written to a temporary file and renamed into place, so a crash in
+// the middle leaves the previous snapshot and the log intact.
+func (store *DiskStore) Checkpoint() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.checkpointErr = store.checkpoint()
+	return store.checkpointErr
+}
+
+// checkpoint takes a checkpoint, the caller holding the log lock.
+func (store *DiskStore) checkpoint() error {
+	if store.pending == 0 {
+		return nil
+	}
+
+	data, err := store.memory.marshalSnapshot()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(store.dir, snapshotFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(store.dir, snapshotFileName)); err != nil {
+		return err
+	}
+
+	if err := store.log.reset(); err != nil {
+		return err
+	}
+
+	store.pending = 0
+	return nil
+}
+
+// GetUser returns the user stored under the given key.
+func (store *DiskStore) GetUser(key string) (models.User, bool) {
+	return store.memory.GetUser(key)
+}
+
+// PutUser stores the user under the given key, replacing any existing one.
+func (store *DiskStore) PutUser(key string, user models.User) error {
+	return store.write(walRecord{Op: opPutUser, Key: key, User: &user})
+}
+
+// DeleteUser removes the user stored under the given key.
+func (store *DiskStore) DeleteUser(key string) error {
+	return store.write(walRecord{Op: opDeleteUser, Key: key})
+}
+
+// ListUsers returns all stored users in no particular order.
+func (store *DiskStore) ListUsers() []models.User {
+	return store.memory.ListUsers()
+}
+
+// GetFolder returns the folder with given ID.
+func (store *DiskStore) GetFolder(id int) (models.Folder, bool) {
+	return store.memory.GetFolder(id)
+}
+
+// PutFolder stores the folder under the given ID, replacing any existing one.
+func (store *DiskStore) PutFolder(id int, folder models.Folder) error {
+	return store.write(walRecord{Op: opPutFolder, ID: id, Folder: &folder})
+}
+
+// DeleteFolder removes the folder with given ID.
+func (store *DiskStore) DeleteFolder(id int) error {
+	return store.write(walRecord{Op: opDeleteFolder, ID: id})
+}
+
+// ListFolders returns all stored folders in no particular order.
+func (store *DiskStore) ListFolders() []models.Folder {
+	return store.memory.ListFolders()
+}
+
+// NextFolderID reserves an unused folder ID.
+// The reservation is logged so IDs are not handed out twice across restarts.
+func (store *DiskStore) NextFolderID() (int, error) {
+	id, err := store.memory.NextFolderID()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := store.write(walRecord{Op: opNextFolderID, ID: id}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetFile returns the file with given ID.
+func (store *DiskStore) GetFile(id int) (models.File, bool) {
+	return store.memory.GetFile(id)
+}
+
+// PutFile stores the file under the given ID, replacing any existing one.
+func (store *DiskStore) PutFile(id int, file models.File) error {
+	return store.write(walRecord{Op: opPutFile, ID: id, File: &file})
+}
+
+// DeleteFile removes the file with given ID.
+func (store *DiskStore) DeleteFile(id int) error {
+	return store.write(walRecord{Op: opDeleteFile, ID: id})
+}
+
+// ListFiles returns all stored files in no particular order.
+func (store *DiskStore) ListFiles() []models.File {
+	return store.memory.ListFiles()
+}
+
+// NextFileID reserves an unused file ID.
+// The reservation is logged so IDs are not handed out twice across restarts.
+func (store *DiskStore) NextFileID() (int, error) {
+	id, err := store.memory.NextFileID()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := store.write(walRecord{Op: opNextFileID, ID: id}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetBlob returns the content with given checksum.
+func (store *DiskStore) GetBlob(checksum string) ([]byte, bool) {
+	return store.memory.GetBlob(checksum)
+}
+
+// PutBlob stores the content under the given checksum.
+func (store *DiskStore) PutBlob(checksum string, data []byte) error {
+	return store.write(walRecord{Op: opPutBlob, Key: checksum, Data: data})
+}
+
+// DeleteBlob removes the content with given checksum.
+func (store *DiskStore) DeleteBlob(checksum string) error {
+	return store.write(walRecord{Op: opDeleteBlob, Key: checksum})
+}
+
+// GetGrant returns the grant stored under the given key.
+func (store *DiskStore) GetGrant(key string) (models.Grant, bool) {
+	return store.memory.GetGrant(key)
+}
+
+// PutGrant stores the grant under the given key, replacing any existing one.
+func (store *DiskStore) PutGrant(key string, grant models.Grant) error {
+	return store.write(walRecord{Op: opPutGrant, Key: key, Grant: &grant})
+}
+
+// DeleteGrant removes the grant stored under the given key.
+func (store *DiskStore) DeleteGrant(key string) error {
+	return store.write(walRecord{Op: opDeleteGrant, Key: key})
+}
+
+// ListGrants returns all stored grants in no particular order.
+func (store *DiskStore) ListGrants() []models.Grant {
+	return store.memory.ListGrants()
+}
+
+// GetGroup returns the group stored under the given key.
+func (store *DiskStore) GetGroup(key string) (models.Group, bool) {
+	return store.memory.GetGroup(key)
+}
+
+// PutGroup stores the group under the given key, replacing any existing one.
+func (store *DiskStore) PutGroup(key string, group models.Group) error {
+	return store.write(walRecord{Op: opPutGroup, Key: key, Group: &group})
+}
+
+// DeleteGroup removes the group stored under the given key.
+func (store *DiskStore) DeleteGroup(key string) error {
+	return store.write(walRecord{Op: opDeleteGroup, Key: key})
+}
+
+// ListGroups returns all stored groups in no particular order.
+func (store *DiskStore) ListGroups() []models.Group {
+	return store.memory.ListGroups()
+}
+
+// GetTrash returns the item with given ID.
+func (store *DiskStore) GetTrash(id int) (models.TrashItem, bool) {
+	return store.memory.GetTrash(id)
+}
+
+// PutTrash stores the item under the given ID, replacing any existing one.
+func (store *DiskStore) PutTrash(id int, item models.TrashItem) error {
+	return store.write(walRecord{Op: opPutTrash, ID: id, Trash: &item})
+}
+
+// DeleteTrash removes the item with given ID.
+func (store *DiskStore) DeleteTrash(id int) error {
+	return store.write(walRecord{Op: opDeleteTrash, ID: id})
+}
+
+// ListTrash returns all stored items in no particular order.
+func (store *DiskStore) ListTrash() []models.TrashItem {
+	return store.memory.ListTrash()
+}
+
+// NextTrashID reserves an unused item ID.
+// The reservation is logged so IDs are not handed out twice across restarts.
+func (store *DiskStore) NextTrashID() (int, error) {
+	id, err := store.memory.NextTrashID()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := store.write(walRecord{Op: opNextTrashID, ID: id}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Batch runs fn against a view of the store whose writes are logged as a single record once fn returns,
+// so either all of them survive a crash or none of them do. Nothing is written if fn returns an error.
+func (store *DiskStore) Batch(fn func(Store) error) error {
+	batch := &batchStore{Store: store}
+	if err := fn(batch); err != nil {
+		return err
+	}
+
+	if len(batch.records) == 0 {
+		return nil
+	}
+
+	return store.write(walRecord{Op: opBatch, Records: batch.records})
+}
+
+// write logs the record, applies it in memory and checkpoints when enough records piled up.
+// The record is durable once logged, so a failing checkpoint does not fail the write:
+// it is kept for CheckpointErr and taken again on the next write.
+func (store *DiskStore) write(record walRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.log.append(record); err != nil {
+		return err
+	}
+
+	if err := store.apply(record); err != nil {
+		return err
+	}
+
+	store.pending++
+	if store.pending >= store.checkpointInterval {
+		store.checkpointErr = store.checkpoint()
+	}
+
+	return nil
+}
+
+// CheckpointErr returns the error of the latest checkpoint taken after a write, nil if it succeeded.
+// The log keeps growing until a checkpoint succeeds again, which is attempted on every write meanwhile.
+func (store *DiskStore) CheckpointErr() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.checkpointErr
+}
+
+// apply performs the logged operation against the in-memory state.
+func (store *DiskStore) apply(record walRecord) error {
+	return store.memory.apply(record)
+}
+
+// marshalSnapshot encodes the whole store as a snapshot, holding the read lock meanwhile.
+func (store *MemoryStore) marshalSnapshot() ([]byte, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	snap := snapshot{
+		Users:        store.users,
+		Folders:      make(map[int]models.Folder, len(store.folders)),
+		Files:        store.files,
+		Blobs:        store.blobs,
+		Grants:       store.grants,
+		Groups:       store.groups,
+		Trash:        store.trash,
+		NextFolderID: store.nextFolderID,
+		NextFileID:   store.nextFileID,
+		NextTrashID:  store.nextTrashID,
+	}
+	for id, folder := range store.folders {
+		snap.Folders[id] = *folder
+	}
+
+	return json.Marshal(snap)
+}
+
+func (store *DiskStore) loadSnapshot() error {
+	data, err := ioutil.ReadFile(filepath.Join(store.dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("corrupted snapshot: %w", err)
+	}
+
+	for key, user := range snap.Users {
+		store.memory.users[key] = user
+	}
+
+	for id, folder := range snap.Folders {
+		folder := folder
+		store.memory.folders[id] = &folder
+	}
+
+	for id, file := range snap.Files {
+		store.memory.files[id] = file
+	}
+
+	for checksum, data := range snap.Blobs {
+		store.memory.blobs[checksum] = data
+	}
+
+	for key, grant := range snap.Grants {
+		store.memory.grants[key] = grant
+	}
+
+	for key, group := range snap.Groups {
+		store.memory.groups[key] = group
+	}
+
+	for id, item := range snap.Trash {
+		store.memory.trash[id] = item
+	}
+
+	if snap.NextFolderID > store.memory.nextFolderID {
+		store.memory.nextFolderID = snap.NextFolderID
+	}
+
+	if snap.NextFileID > store.memory.nextFileID {
+		store.memory.nextFileID = snap.NextFileID
+	}
+
+	if snap.NextTrashID > store.memory.nextTrashID {
+		store.memory.nextTrashID = snap.NextTrashID
+	}
+
+	return nil
+}
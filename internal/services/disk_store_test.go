@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+func TestDiskStore_Reopen(t *testing.T) {
+	tests := []struct {
+		name               string
+		checkpointInterval int
+		tornTail           string
+	}{
+		{
+			name:               "01. it should replay the write-ahead log on startup.",
+			checkpointInterval: 1000,
+		},
+		{
+			name:               "02. it should restore from the snapshot after a checkpoint.",
+			checkpointInterval: 2,
+		},
+		{
+			name:               "03. it should drop a torn last record.",
+			checkpointInterval: 1000,
+			tornTail:           `1234abcd {"op":"put_us`,
+		},
+		{
+			name:               "04. it should drop a last record with a bad checksum.",
+			checkpointInterval: 1000,
+			tornTail:           "00000000 {\"op\":\"put_user\",\"key\":\"mark\",\"user\":{\"Name\":\"Mark\"}}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "vfs")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			store, err := OpenDiskStore(dir, tt.checkpointInterval)
+			if err != nil {
+				t.Fatalf("OpenDiskStore() error = %v", err)
+			}
+
+			id, _ := store.NextFolderID()
+			store.PutUser("luke", models.User{Name: "Luke"})
+			store.PutFolder(id, models.Folder{ID: id, Name: "Work", CreatedBy: "Luke"})
+			store.PutBlob("abc", []byte("hello"))
+			store.PutFile(1, models.File{ID: 1, Name: "1.tc", FolderID: id, Checksum: "abc", Size: 5})
+
+			// Simulate a crash: the log is left as it is without a final checkpoint.
+			store.log.close()
+
+			if tt.tornTail != "" {
+				f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.WriteString(tt.tornTail)
+				f.Close()
+			}
+
+			reopened, err := OpenDiskStore(dir, tt.checkpointInterval)
+			if err != nil {
+				t.Fatalf("OpenDiskStore() error = %v", err)
+			}
+			defer reopened.Close()
+
+			if _, exists := reopened.GetUser("luke"); !exists {
+				t.Errorf("DiskStore.GetUser() user is lost after reopen")
+			}
+			if _, exists := reopened.GetUser("mark"); exists {
+				t.Errorf("DiskStore.GetUser() torn record is applied")
+			}
+			if folder, exists := reopened.GetFolder(id); !exists || folder.Name != "Work" {
+				t.Errorf("DiskStore.GetFolder() = %v, %v", folder, exists)
+			}
+			if data, _ := reopened.GetBlob("abc"); string(data) != "hello" {
+				t.Errorf("DiskStore.GetBlob() = %q, want %q", data, "hello")
+			}
+			if file, exists := reopened.GetFile(1); !exists || file.FolderID != id {
+				t.Errorf("DiskStore.GetFile() = %v, %v", file, exists)
+			}
+			if next, _ := reopened.NextFolderID(); next == id {
+				t.Errorf("DiskStore.NextFolderID() reused folder ID %v", id)
+			}
+		})
+	}
+}
+
+func TestDiskStore_Batch(t *testing.T) {
+	tests := []struct {
+		name     string
+		failWith error
+		torn     bool
+		want     bool
+	}{
+		{
+			name: "01. it should persist every write of the batch.",
+			want: true,
+		},
+		{
+			name:     "02. it should write nothing if the batch fails.",
+			failWith: errors.New("failed"),
+			want:     false,
+		},
+		{
+			name: "03. it should drop every write of a torn batch record.",
+			torn: true,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "vfs")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			store, err := OpenDiskStore(dir, 1000)
+			if err != nil {
+				t.Fatalf("OpenDiskStore() error = %v", err)
+			}
+			store.PutFolder(1001, models.Folder{ID: 1001, Name: "Work", CreatedBy: "Luke"})
+			store.PutFile(1, models.File{ID: 1, Name: "1.tc", FolderID: 1001})
+
+			err = store.Batch(func(batch Store) error {
+				batch.DeleteFile(1)
+				batch.DeleteFolder(1001)
+				return tt.failWith
+			})
+			if err != tt.failWith {
+				t.Fatalf("DiskStore.Batch() error = %v, want %v", err, tt.failWith)
+			}
+			store.log.close()
+
+			if tt.torn {
+				path := filepath.Join(dir, walFileName)
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Truncate(path, info.Size()-10); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reopened, err := OpenDiskStore(dir, 1000)
+			if err != nil {
+				t.Fatalf("OpenDiskStore() error = %v", err)
+			}
+			defer reopened.Close()
+
+			_, fileExists := reopened.GetFile(1)
+			_, folderExists := reopened.GetFolder(1001)
+			if fileExists == tt.want || folderExists == tt.want {
+				t.Errorf("DiskStore.Batch() file exists = %v, folder exists = %v, want deleted = %v", fileExists, folderExists, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiskStore_Concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := OpenDiskStore(dir, 7)
+	if err != nil {
+		t.Fatalf("OpenDiskStore() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				id, err := store.NextFolderID()
+				if err != nil {
+					t.Errorf("DiskStore.NextFolderID() error = %v", err)
+					return
+				}
+
+				store.Batch(func(batch Store) error {
+					batch.PutFolder(id, models.Folder{ID: id, Name: "Work"})
+					return batch.PutGrant(makeGrantKey(id, "mark"), models.Grant{FolderID: id, Grantee: "mark"})
+				})
+				store.ListFolders()
+				store.ListGrants()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("DiskStore.Close() error = %v", err)
+	}
+
+	reopened, err := OpenDiskStore(dir, 7)
+	if err != nil {
+		t.Fatalf("OpenDiskStore() error = %v", err)
+	}
+	defer reopened.Close()
+
+	if got := len(reopened.ListFolders()); got != 400 {
+		t.Errorf("DiskStore.ListFolders() = %d folders, want 400", got)
+	}
+
+	if got := len(reopened.ListGrants()); got != 400 {
+		t.Errorf("DiskStore.ListGrants() = %d grants, want 400", got)
+	}
+}
+
+func TestDiskStore_FailingCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := OpenDiskStore(dir, 1)
+	if err != nil {
+		t.Fatalf("OpenDiskStore() error = %v", err)
+	}
+
+	// A directory in place of the snapshot makes renaming the new snapshot fail.
+	blocker := filepath.Join(dir, snapshotFileName)
+	if err := os.MkdirAll(filepath.Join(blocker, "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.PutUser("luke", models.User{Name: "Luke"}); err != nil {
+		t.Errorf("DiskStore.PutUser() error = %v, want the write to succeed", err)
+	}
+	if store.CheckpointErr() == nil {
+		t.Errorf("DiskStore.CheckpointErr() = nil, want the checkpoint error")
+	}
+
+	os.RemoveAll(blocker)
+
+	if err := store.PutUser("mark", models.User{Name: "Mark"}); err != nil {
+		t.Errorf("DiskStore.PutUser() error = %v", err)
+	}
+	if err := store.CheckpointErr(); err != nil {
+		t.Errorf("DiskStore.CheckpointErr() = %v, want the checkpoint to be taken again", err)
+	}
+
+	store.log.close()
+
+	reopened, err := OpenDiskStore(dir, 1)
+	if err != nil {
+		t.Fatalf("OpenDiskStore() error = %v", err)
+	}
+	defer reopened.Close()
+
+	for _, name := range []string{"luke", "mark"} {
+		if _, exists := reopened.GetUser(name); !exists {
+			t.Errorf("DiskStore.GetUser() user %s is lost after reopen", name)
+		}
+	}
+}
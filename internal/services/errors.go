@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The sentinel errors the failures of the services match with errors.Is,
+// so callers can react to the condition rather than to the wording of the message.
+var (
+	// ErrNotFound means the user, folder, file or other entity does not exist.
+	ErrNotFound = errors.New("does not exist")
+
+	// ErrAlreadyExists means the name is already taken.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrPermissionDenied means the user is not allowed to perform the operation.
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrUnauthenticated means the credentials of the user are wrong.
+	ErrUnauthenticated = errors.New("authentication failed")
+
+	// ErrConflict means the operation does not fit the current state, such as deleting a non-empty folder.
+	ErrConflict = errors.New("conflict with the current state")
+
+	// ErrInvalid means an argument is malformed, such as an empty name.
+	ErrInvalid = errors.New("invalid argument")
+)
+
+// Error is a failure of a service, telling which entity it is about.
+// It matches its sentinel error with errors.Is:
+//
+//	var e *services.Error
+//	if errors.As(err, &e) && errors.Is(err, services.ErrNotFound) {
+//		fmt.Println(e.Entity, e.ID, "is gone")
+//	}
+type Error struct {
+	// Err is the sentinel error of the failure, such as ErrNotFound.
+	Err error
+
+	// Entity is the kind of entity the failure is about, such as "user", "folder" or "file".
+	Entity string
+
+	// ID identifies the entity, such as the user name, the folder ID or the file name.
+	ID string
+
+	// Message describes the failure in the words shown to the user.
+	Message string
+}
+
+// Error returns the message describing the failure.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel error of the failure.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError returns the failure about the entity with given ID, described by the message.
+func newError(err error, entity string, id interface{}, message string) error {
+	return &Error{Err: err, Entity: entity, ID: fmt.Sprint(id), Message: message}
+}
+
+// errNotFound returns the failure of the entity with given ID not existing.
+func errNotFound(entity string, id interface{}) error {
+	return newError(ErrNotFound, entity, id, entity+" does not exist")
+}
+
+// errUnknownUser returns the failure of the user acting not existing.
+func errUnknownUser(username string) error {
+	return errNotFound("user", username)
+}
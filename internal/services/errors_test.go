@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	factory := &Factory{}
+	factory.GetUserService().Register("luke", "secret")
+	factory.GetUserService().Register("mark", "")
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+	factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader("hello"))
+	lost, _ := factory.GetFileService().Upload("luke", work.ID, "2.tc", "", strings.NewReader("lost"))
+	factory.store.DeleteBlob(lost.Checksum)
+
+	tests := []struct {
+		name       string
+		op         func() error
+		want       error
+		wantEntity string
+		wantID     string
+	}{
+		{
+			name:       "01. it should return ErrNotFound for unknown users.",
+			op:         func() error { _, err := factory.GetFolderService().Create("Home", "john", ""); return err },
+			want:       ErrNotFound,
+			wantEntity: "user",
+			wantID:     "john",
+		},
+		{
+			name:       "02. it should return ErrNotFound for unknown folders.",
+			op:         func() error { return factory.GetFolderService().Rename(9999, "Other", "luke") },
+			want:       ErrNotFound,
+			wantEntity: "folder",
+			wantID:     "9999",
+		},
+		{
+			name: "03. it should return ErrAlreadyExists for taken file names.",
+			op: func() error {
+				_, err := factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader(""))
+				return err
+			},
+			want:       ErrAlreadyExists,
+			wantEntity: "file",
+			wantID:     "1.tc",
+		},
+		{
+			name:       "04. it should return ErrPermissionDenied for the folders of other users.",
+			op:         func() error { return factory.GetFolderService().Rename(work.ID, "Other", "mark") },
+			want:       ErrPermissionDenied,
+			wantEntity: "folder",
+		},
+		{
+			name:       "05. it should return ErrUnauthenticated for wrong passwords.",
+			op:         func() error { return factory.GetUserService().Authenticate("luke", "wrong") },
+			want:       ErrUnauthenticated,
+			wantEntity: "user",
+			wantID:     "luke",
+		},
+		{
+			name:       "06. it should return ErrConflict for non-empty folders.",
+			op:         func() error { return factory.GetFolderService().Delete(work.ID, "luke", false) },
+			want:       ErrConflict,
+			wantEntity: "folder",
+		},
+		{
+			name:       "07. it should return ErrInvalid for empty names.",
+			op:         func() error { _, err := factory.GetFolderService().Create(" ", "luke", ""); return err },
+			want:       ErrInvalid,
+			wantEntity: "folder",
+		},
+		{
+			name:       "08. it should return ErrNotFound for the files whose content is missing.",
+			op:         func() error { _, err := factory.GetFileService().Open("luke", work.ID, "2.tc"); return err },
+			want:       ErrNotFound,
+			wantEntity: "file",
+			wantID:     "2.tc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false, want true", err, tt.want)
+			}
+
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if e.Entity != tt.wantEntity {
+				t.Errorf("Error.Entity = %q, want %q", e.Entity, tt.wantEntity)
+			}
+			if tt.wantID != "" && e.ID != tt.wantID {
+				t.Errorf("Error.ID = %q, want %q", e.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -1,58 +1,176 @@
 package services
 
-import "virtual-file-system/internal/models"
+import (
+	"sync"
+	"time"
+)
 
 // Factory manages service instances across services package.
+// The services are all created together the first time one of them is requested,
+// after which they are safe for concurrent use.
 type Factory struct {
+	once sync.Once
+
+	store         Store
+	locks         *lockTable
+	authorizer    Authorizer
 	userService   UserService
 	folderService FolderService
 	fileService   FileService
+	shareService  ShareService
+	groupService  GroupService
+	trashService  TrashService
+
+	maxVersions    int
+	trashRetention time.Duration
 }
 
-var instance *Factory
+var (
+	instance     *Factory
+	instanceOnce sync.Once
+)
+
+// NewFactory returns a factory with the default settings, whose services share an in-memory store
+// unless another one is set.
+func NewFactory() *Factory {
+	return &Factory{
+		maxVersions:    DefaultMaxVersions,
+		trashRetention: DefaultTrashRetention,
+	}
+}
 
 // GetFactory returns singleton instance of service factory
 func GetFactory() *Factory {
-	if instance == nil {
-		instance = &Factory{}
-	}
+	instanceOnce.Do(func() {
+		instance = NewFactory()
+	})
 
 	return instance
 }
 
+// SetStore replaces the Store shared by all services.
+// It should be called before any service is requested, later calls are ignored by the services.
+func (f *Factory) SetStore(store Store) {
+	f.store = store
+}
+
+// SetMaxVersions sets the number of revisions retained per file, zero or less keeps every revision.
+// It should be called before the FileService is requested.
+func (f *Factory) SetMaxVersions(n int) {
+	f.maxVersions = n
+}
+
+// SetTrashRetention sets how long deleted items are kept in the trash, zero or less keeps them until emptied.
+// It should be called before the TrashService is requested.
+func (f *Factory) SetTrashRetention(retention time.Duration) {
+	f.trashRetention = retention
+}
+
+// GetStore returns the Store shared by all services, which is kept in memory by default.
+func (f *Factory) GetStore() Store {
+	f.init()
+	return f.store
+}
+
+// GetAuthorizer returns the Authorizer consulted by all services.
+func (f *Factory) GetAuthorizer() Authorizer {
+	f.init()
+	return f.authorizer
+}
+
 // GetUserService returns an instance of UserService
 func (f *Factory) GetUserService() UserService {
-	if f.userService == nil {
-		f.userService = &UserServiceImpl{
-			make(map[string]models.User),
-		}
-	}
-
+	f.init()
 	return f.userService
 }
 
 // GetFolderService returns an instance of FolderService
 func (f *Factory) GetFolderService() FolderService {
-	if f.folderService == nil {
-		f.folderService = &FolderServiceImpl{
-			folders:     make(map[int]*models.Folder),
-			userService: f.GetUserService(),
-			nextKey:     1001,
-		}
-	}
-
+	f.init()
 	return f.folderService
 }
 
 // GetFileService returns an instance of FileService
 func (f *Factory) GetFileService() FileService {
-	if f.fileService == nil {
+	f.init()
+	return f.fileService
+}
+
+// GetShareService returns an instance of ShareService
+func (f *Factory) GetShareService() ShareService {
+	f.init()
+	return f.shareService
+}
+
+// GetGroupService returns an instance of GroupService
+func (f *Factory) GetGroupService() GroupService {
+	f.init()
+	return f.groupService
+}
+
+// GetTrashService returns an instance of TrashService
+func (f *Factory) GetTrashService() TrashService {
+	f.init()
+	return f.trashService
+}
+
+// init creates the services once, all sharing the store and the locks.
+func (f *Factory) init() {
+	f.once.Do(func() {
+		if f.store == nil {
+			f.store = NewMemoryStore()
+		}
+		f.locks = newLockTable()
+
+		f.authorizer = &AuthorizerImpl{
+			store: f.store,
+		}
+
+		userService := &UserServiceImpl{
+			store: f.store,
+			locks: f.locks,
+		}
+		f.userService = userService
+
+		folderService := &FolderServiceImpl{
+			store:       f.store,
+			userService: userService,
+			authorizer:  f.authorizer,
+			locks:       f.locks,
+		}
+		f.folderService = folderService
+
+		groupService := &GroupServiceImpl{
+			store:       f.store,
+			userService: userService,
+			locks:       f.locks,
+		}
+		f.groupService = groupService
+
 		f.fileService = &FileServiceImpl{
-			files:         make(map[string]models.File),
-			userService:   f.GetUserService(),
-			folderService: f.GetFolderService(),
+			store:         f.store,
+			userService:   userService,
+			folderService: folderService,
+			authorizer:    f.authorizer,
+			locks:         f.locks,
+			maxVersions:   f.maxVersions,
 		}
-	}
 
-	return f.fileService
+		f.shareService = &ShareServiceImpl{
+			store:         f.store,
+			userService:   userService,
+			folderService: folderService,
+			groupService:  groupService,
+			authorizer:    f.authorizer,
+			locks:         f.locks,
+		}
+
+		f.trashService = &TrashServiceImpl{
+			store:       f.store,
+			userService: userService,
+			authorizer:  f.authorizer,
+			locks:       f.locks,
+			retention:   f.trashRetention,
+		}
+	})
 }
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+// yieldingStore gives way to other goroutines after every read, so concurrent callers
+// interleave in the middle of their operations even on a single CPU.
+type yieldingStore struct {
+	Store
+}
+
+func (store yieldingStore) GetUser(key string) (models.User, bool) {
+	defer runtime.Gosched()
+	return store.Store.GetUser(key)
+}
+
+func (store yieldingStore) GetFolder(id int) (models.Folder, bool) {
+	defer runtime.Gosched()
+	return store.Store.GetFolder(id)
+}
+
+func (store yieldingStore) ListFolders() []models.Folder {
+	defer runtime.Gosched()
+	return store.Store.ListFolders()
+}
+
+func (store yieldingStore) ListFiles() []models.File {
+	defer runtime.Gosched()
+	return store.Store.ListFiles()
+}
+
+func (store yieldingStore) GetGroup(key string) (models.Group, bool) {
+	defer runtime.Gosched()
+	return store.Store.GetGroup(key)
+}
+
+func (store yieldingStore) GetTrash(id int) (models.TrashItem, bool) {
+	defer runtime.Gosched()
+	return store.Store.GetTrash(id)
+}
+
+func (store yieldingStore) ListTrash() []models.TrashItem {
+	defer runtime.Gosched()
+	return store.Store.ListTrash()
+}
+
+// newYieldingFactory returns a factory whose services share a yieldingStore.
+func newYieldingFactory() (*Factory, *MemoryStore) {
+	memory := NewMemoryStore()
+
+	factory := NewFactory()
+	factory.SetStore(yieldingStore{memory})
+
+	return factory, memory
+}
+
+// runConcurrently calls fn from n goroutines at once and returns how many calls succeeded.
+func runConcurrently(n int, fn func(i int) error) int {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			if err := fn(i); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return succeeded
+}
+
+func TestFactory_ConcurrentNames(t *testing.T) {
+	factory, _ := newYieldingFactory()
+	factory.GetUserService().Register("luke", "")
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+	factory.GetFileService().Upload("luke", work.ID, "taken.tc", "", nil)
+
+	tests := []struct {
+		name string
+		fn   func(i int) error
+	}{
+		{
+			name: "01. it should register a user name only once.",
+			fn: func(i int) error {
+				return factory.GetUserService().Register("Mark", "")
+			},
+		},
+		{
+			name: "02. it should create a folder name only once.",
+			fn: func(i int) error {
+				_, err := factory.GetFolderService().Create("/Work/Reports", "luke", "")
+				return err
+			},
+		},
+		{
+			name: "03. it should give a folder a name only once.",
+			fn: func(i int) error {
+				f, err := factory.GetFolderService().Create(fmt.Sprintf("Draft%d", i), "luke", "")
+				if err != nil {
+					return err
+				}
+				return factory.GetFolderService().Rename(f.ID, "Final", "luke")
+			},
+		},
+		{
+			name: "04. it should upload a file name only once.",
+			fn: func(i int) error {
+				_, err := factory.GetFileService().Upload("luke", work.ID, "1.tc", "", strings.NewReader(fmt.Sprint(i)))
+				return err
+			},
+		},
+		{
+			name: "05. it should move a file to a taken name only once.",
+			fn: func(i int) error {
+				name := fmt.Sprintf("%d.tmp", i)
+				if _, err := factory.GetFileService().Upload("luke", work.ID, name, "", nil); err != nil {
+					return err
+				}
+				_, err := factory.GetFileService().Move("luke", work.ID, name, work.ID, "moved.tc")
+				return err
+			},
+		},
+		{
+			name: "06. it should create a group only once.",
+			fn: func(i int) error {
+				_, err := factory.GetGroupService().Create("squad", "luke")
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runConcurrently(20, tt.fn); got != 1 {
+				t.Errorf("succeeded %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestFactory_ConcurrentVersions(t *testing.T) {
+	factory, memory := newYieldingFactory()
+	factory.SetMaxVersions(5)
+	factory.GetUserService().Register("luke", "")
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+
+	n := 50
+	runConcurrently(n, func(i int) error {
+		_, err := factory.GetFileService().UploadVersion("luke", work.ID, "1.tc", "", strings.NewReader(fmt.Sprint(i)))
+		return err
+	})
+
+	versions, err := factory.GetFileService().ListVersions("luke", work.ID, "1.tc")
+	if err != nil {
+		t.Fatalf("FileService.ListVersions() error = %v", err)
+	}
+
+	if len(versions) != 5 || versions[len(versions)-1].Number != n {
+		t.Fatalf("FileService.ListVersions() = %d versions up to %d, want 5 up to %d", len(versions), versions[len(versions)-1].Number, n)
+	}
+
+	checkBlobs(t, factory.GetStore())
+
+	// Only the contents of the retained revisions are kept.
+	if got := len(memory.blobs); got != 5 {
+		t.Errorf("stored %d contents, want 5", got)
+	}
+}
+
+func TestFactory_ConcurrentTree(t *testing.T) {
+	factory, _ := newYieldingFactory()
+	factory.SetTrashRetention(0)
+	factory.GetUserService().Register("luke", "")
+	factory.GetUserService().Register("mark", "")
+
+	folders := factory.GetFolderService()
+	files := factory.GetFileService()
+	trash := factory.GetTrashService()
+
+	runConcurrently(40, func(i int) error {
+		name := fmt.Sprintf("/Work%d", i%4)
+		folders.Create(name, "luke", "")
+
+		f, err := folders.Resolve("luke", name)
+		if err != nil {
+			return err
+		}
+
+		switch i % 5 {
+		case 0:
+			folders.Delete(f.ID, "luke", true)
+		case 1:
+			items, _ := trash.List("luke")
+			for _, item := range items {
+				trash.Restore(item.ID, "luke")
+			}
+		case 2:
+			trash.Empty("luke")
+		case 3:
+			sub, err := folders.Create(name+"/Sub", "luke", "")
+			if err == nil {
+				folders.Move(sub.ID, 0, fmt.Sprintf("Sub%d", i), "luke")
+			}
+		}
+
+		for j := 0; j < 5; j++ {
+			files.UploadVersion("luke", f.ID, fmt.Sprintf("%d.tc", j), "", strings.NewReader(fmt.Sprint(i, j)))
+			files.GetAll("luke", f.ID, "", "")
+			folders.GetAll("mark", "", "")
+		}
+
+		return nil
+	})
+
+	store := factory.GetStore()
+	for _, file := range store.ListFiles() {
+		if _, exists := store.GetFolder(file.FolderID); !exists {
+			t.Errorf("file %d is under the missing folder %d", file.ID, file.FolderID)
+		}
+	}
+
+	for _, folder := range store.ListFolders() {
+		if _, exists := store.GetFolder(folder.ParentID); folder.ParentID != 0 && !exists {
+			t.Errorf("folder %d is under the missing folder %d", folder.ID, folder.ParentID)
+		}
+	}
+
+	checkBlobs(t, store)
+}
+
+// checkBlobs fails unless every content referred to by a file or a deleted file is stored.
+func checkBlobs(t *testing.T, store Store) {
+	t.Helper()
+
+	files := store.ListFiles()
+	for _, item := range store.ListTrash() {
+		files = append(files, item.Files...)
+	}
+
+	for _, file := range files {
+		for _, v := range versionsOf(file) {
+			if _, exists := store.GetBlob(v.Checksum); !exists {
+				t.Errorf("content %s of file %d is missing", v.Checksum, file.ID)
+			}
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package services
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -10,79 +16,412 @@ import (
 )
 
 // FileService is responsible for CRUD operations against a file
+//
+// The methods ending in Context give up once the context is done, returning its error,
+// and act on behalf of the user carried by the context, see WithUser, when given an empty user name.
 type FileService interface {
-	Upload(createdBy string, folderID int, filename string, desc string) error
+	Upload(createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error)
+	UploadContext(ctx context.Context, createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error)
+
+	UploadVersion(createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error)
+	UploadVersionContext(ctx context.Context, createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error)
+
+	ListVersions(username string, folderID int, filename string) ([]models.FileVersion, error)
+	ListVersionsContext(ctx context.Context, username string, folderID int, filename string) ([]models.FileVersion, error)
+
+	RestoreVersion(restoredBy string, folderID int, filename string, number int) (*models.File, error)
+	RestoreVersionContext(ctx context.Context, restoredBy string, folderID int, filename string, number int) (*models.File, error)
+
+	Open(username string, folderID int, filename string) (io.ReadCloser, error)
+	OpenContext(ctx context.Context, username string, folderID int, filename string) (io.ReadCloser, error)
+
+	Get(username string, id int) (*models.File, error)
+	GetContext(ctx context.Context, username string, id int) (*models.File, error)
+
 	Delete(deletedBy string, folderID int, filename string) error
+	DeleteContext(ctx context.Context, deletedBy string, folderID int, filename string) error
+
+	Move(movedBy string, folderID int, filename string, destFolderID int, destName string) (*models.File, error)
+	MoveContext(ctx context.Context, movedBy string, folderID int, filename string, destFolderID int, destName string) (*models.File, error)
+
 	GetAll(username string, folderID int, sortBy string, sortOrder string) ([]models.File, error)
+	GetAllContext(ctx context.Context, username string, folderID int, sortBy string, sortOrder string) ([]models.File, error)
 }
 
 // FileServiceImpl is the implementation of the FileService
 type FileServiceImpl struct {
-	files         map[string]models.File
+	store         Store
 	userService   UserService
 	folderService FolderService
+	authorizer    Authorizer
+	locks         *lockTable
+
+	// maxVersions is the number of revisions retained per file, the oldest ones are dropped first.
+	// Zero or less keeps every revision.
+	maxVersions int
+}
+
+// DefaultMaxVersions is the number of revisions retained per file unless configured otherwise.
+const DefaultMaxVersions = 10
+
+// Upload creates the file under the folder with given ID and stores the bytes read from content.
+// A nil content creates an empty file.
+// File names are unique within a folder using case insensitive comparison.
+// An error will be returned if the folder or the user is not found on the system,
+// or if the user is not allowed to write the folder.
+func (service *FileServiceImpl) Upload(createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error) {
+	return service.UploadContext(context.Background(), createdBy, folderID, filename, desc, content)
 }
 
-// Upload creates the file under the folder with given ID.
-// An error will be returned if the folder or the user is not found on the system.
-func (service *FileServiceImpl) Upload(createdBy string, folderID int, filename string, desc string) error {
+// UploadContext is Upload with a context.
+func (service *FileServiceImpl) UploadContext(ctx context.Context, createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error) {
+	createdBy = actingUser(ctx, createdBy)
 	if !service.userService.Exists(createdBy) {
-		return errors.New("authentication failed")
+		return nil, errUnknownUser(createdBy)
 	}
 
-	if !service.folderService.Exists(folderID) {
-		return errors.New("folder does not exist")
+	// The content is read before taking the locks, so a slow upload does not hold up the others.
+	version, data, err := readContent(ctx, createdBy, desc, content)
+	if err != nil {
+		return nil, err
 	}
 
-	if _, exists := service.files[filename]; exists {
-		return errors.New("file already exists")
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(folderID))()
+
+	return service.upload(ctx, createdBy, folderID, filename, version, data)
+}
+
+// upload creates the file under the folder with the content already read, the caller holding the lock of the folder.
+func (service *FileServiceImpl) upload(ctx context.Context, createdBy string, folderID int, filename string, version *models.FileVersion, data []byte) (*models.File, error) {
+	folder, err := service.folderService.GetContext(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(createdBy, *folder, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(filename) == "" || strings.Contains(filename, pathSeparator) {
+		return nil, newError(ErrInvalid, "file", filename, "file name should not be empty or contain \"/\"")
+	}
+
+	if service.find(folderID, filename) != nil {
+		return nil, newError(ErrAlreadyExists, "file", filename, "file already exists")
 	}
 
+	id, err := service.store.NextFileID()
+	if err != nil {
+		return nil, err
+	}
+	version.Number = 1
+
 	file := &models.File{
+		ID:        id,
 		FolderID:  folderID,
 		Name:      filename,
 		Ext:       strings.TrimPrefix(filepath.Ext(filename), "."),
-		Desc:      desc,
-		CreatedAt: time.Now(),
+		CreatedAt: version.CreatedAt,
 		CreatedBy: createdBy,
 	}
+	setVersion(file, *version)
 
-	service.files[filename] = *file
-	return nil
+	if err := service.putFile(*file, data); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// UploadVersion stores the bytes read from content as a new revision of the file under the folder with given ID,
+// keeping the previous revisions up to the configured limit. An empty desc keeps the previous description.
+// The file is created if it does not exist yet.
+// An error will be returned if the folder or the user is not found on the system,
+// or if the user is not allowed to write the file.
+func (service *FileServiceImpl) UploadVersion(createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error) {
+	return service.UploadVersionContext(context.Background(), createdBy, folderID, filename, desc, content)
+}
+
+// UploadVersionContext is UploadVersion with a context.
+func (service *FileServiceImpl) UploadVersionContext(ctx context.Context, createdBy string, folderID int, filename string, desc string, content io.Reader) (*models.File, error) {
+	createdBy = actingUser(ctx, createdBy)
+	if !service.userService.Exists(createdBy) {
+		return nil, errUnknownUser(createdBy)
+	}
+
+	// The content is read before taking the locks, so a slow upload does not hold up the others.
+	version, data, err := readContent(ctx, createdBy, desc, content)
+	if err != nil {
+		return nil, err
+	}
+
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(folderID))()
+
+	folder, err := service.folderService.GetContext(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(createdBy, *folder, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	file := service.find(folderID, filename)
+	if file == nil {
+		return service.upload(ctx, createdBy, folderID, filename, version, data)
+	}
+
+	if err := service.authorizer.AuthorizeFile(createdBy, *file, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	if desc == "" {
+		version.Desc = file.Desc
+	}
+
+	return service.addVersion(file, *version, data)
 }
 
-// Delete removes the specific file under the given folder.
-// An error will be returned if the folder or file or user is not found on the system.
+// ListVersions returns the retained revisions of the specific file under the given folder, oldest first.
+// An error will be returned if the folder or file or user is not found on the system,
+// or if the user is not allowed to read the folder.
+func (service *FileServiceImpl) ListVersions(username string, folderID int, filename string) ([]models.FileVersion, error) {
+	return service.ListVersionsContext(context.Background(), username, folderID, filename)
+}
+
+// ListVersionsContext is ListVersions with a context.
+func (service *FileServiceImpl) ListVersionsContext(ctx context.Context, username string, folderID int, filename string) ([]models.FileVersion, error) {
+	username = actingUser(ctx, username)
+
+	file, err := service.findReadable(ctx, username, folderID, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return versionsOf(*file), nil
+}
+
+// RestoreVersion rolls the specific file under the given folder back to the revision with given number.
+// The content of that revision becomes a new revision, so the history in between is kept.
+// An error will be returned if the folder or file or user is not found on the system,
+// if the revision is no longer retained, or if the user is not allowed to write the file.
+func (service *FileServiceImpl) RestoreVersion(restoredBy string, folderID int, filename string, number int) (*models.File, error) {
+	return service.RestoreVersionContext(context.Background(), restoredBy, folderID, filename, number)
+}
+
+// RestoreVersionContext is RestoreVersion with a context.
+func (service *FileServiceImpl) RestoreVersionContext(ctx context.Context, restoredBy string, folderID int, filename string, number int) (*models.File, error) {
+	restoredBy = actingUser(ctx, restoredBy)
+
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(folderID))()
+
+	file, err := service.findReadable(ctx, restoredBy, folderID, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFile(restoredBy, *file, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	for _, v := range versionsOf(*file) {
+		if v.Number == number {
+			v.CreatedAt = time.Now()
+			v.CreatedBy = restoredBy
+
+			return service.addVersion(file, v, nil)
+		}
+	}
+
+	return nil, newError(ErrNotFound, "version", number, fmt.Sprintf("version %d does not exist", number))
+}
+
+// Open returns a reader over the content of the specific file under the given folder.
+// An error will be returned if the folder or file or user is not found on the system,
+// or if the user is not allowed to read the folder.
+func (service *FileServiceImpl) Open(username string, folderID int, filename string) (io.ReadCloser, error) {
+	return service.OpenContext(context.Background(), username, folderID, filename)
+}
+
+// OpenContext is Open with a context.
+func (service *FileServiceImpl) OpenContext(ctx context.Context, username string, folderID int, filename string) (io.ReadCloser, error) {
+	username = actingUser(ctx, username)
+
+	// The content is read at once, so the file cannot change in between.
+	defer service.locks.rlockTree()()
+	defer service.locks.rlock(folderLockKey(folderID))()
+
+	file, err := service.findReadable(ctx, username, folderID, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, exists := service.store.GetBlob(file.Checksum)
+	if !exists && file.Size > 0 {
+		return nil, newError(ErrNotFound, "file", file.Name, "file content is missing")
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Get returns the file with given ID, which stays the same for the lifetime of the file.
+// An error will be returned if the file or user is not found on the system, or if the user is not allowed to read the file.
+func (service *FileServiceImpl) Get(username string, id int) (*models.File, error) {
+	return service.GetContext(context.Background(), username, id)
+}
+
+// GetContext is Get with a context.
+func (service *FileServiceImpl) GetContext(ctx context.Context, username string, id int) (*models.File, error) {
+	username = actingUser(ctx, username)
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, exists := service.store.GetFile(id)
+	if !exists {
+		return nil, errNotFound("file", id)
+	}
+
+	if err := service.authorizer.AuthorizeFile(username, file, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// Delete moves the specific file under the given folder to the trash of `deletedBy`.
+// An error will be returned if the folder or file or user is not found on the system,
+// or if the user is not allowed to write the file.
 func (service *FileServiceImpl) Delete(deletedBy string, folderID int, filename string) error {
+	return service.DeleteContext(context.Background(), deletedBy, folderID, filename)
+}
+
+// DeleteContext is Delete with a context.
+func (service *FileServiceImpl) DeleteContext(ctx context.Context, deletedBy string, folderID int, filename string) error {
+	deletedBy = actingUser(ctx, deletedBy)
 	if !service.userService.Exists(deletedBy) {
-		return errors.New("authentication failed")
+		return errUnknownUser(deletedBy)
 	}
 
-	if !service.folderService.Exists(folderID) {
-		return errors.New("folder does not exist")
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(folderID))()
+
+	folder, err := service.folderService.GetContext(ctx, folderID)
+	if err != nil {
+		return err
 	}
 
-	if _, exists := service.files[filename]; !exists {
-		return errors.New("file does not exist")
+	if err := service.authorizer.AuthorizeFolder(deletedBy, *folder, PermissionRead); err != nil {
+		return err
 	}
 
-	delete(service.files, filename)
-	return nil
+	file := service.find(folderID, filename)
+	if file == nil {
+		return errNotFound("file", filename)
+	}
+
+	if err := service.authorizer.AuthorizeFile(deletedBy, *file, PermissionWrite); err != nil {
+		return err
+	}
+
+	path, err := service.folderService.PathContext(ctx, folderID)
+	if err != nil {
+		return err
+	}
+
+	return moveToTrash(service.store, models.TrashItem{
+		Path:      path + pathSeparator + file.Name,
+		Files:     []models.File{*file},
+		DeletedBy: deletedBy,
+	})
+}
+
+// Move puts the specific file under the given folder into the destination folder with the new name,
+// keeping its ID and revisions.
+// File names are unique within a folder using case insensitive comparison.
+// An error will be returned if the folders or file or user is not found on the system,
+// or if the user is not allowed to write the file and the destination folder.
+func (service *FileServiceImpl) Move(movedBy string, folderID int, filename string, destFolderID int, destName string) (*models.File, error) {
+	return service.MoveContext(context.Background(), movedBy, folderID, filename, destFolderID, destName)
+}
+
+// MoveContext is Move with a context.
+func (service *FileServiceImpl) MoveContext(ctx context.Context, movedBy string, folderID int, filename string, destFolderID int, destName string) (*models.File, error) {
+	movedBy = actingUser(ctx, movedBy)
+
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(folderID), folderLockKey(destFolderID))()
+
+	file, err := service.findReadable(ctx, movedBy, folderID, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFile(movedBy, *file, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	dest, err := service.folderService.GetContext(ctx, destFolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(movedBy, *dest, PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(destName) == "" || strings.Contains(destName, pathSeparator) {
+		return nil, newError(ErrInvalid, "file", destName, "file name should not be empty or contain \"/\"")
+	}
+
+	if existing := service.find(destFolderID, destName); existing != nil && existing.ID != file.ID {
+		return nil, newError(ErrAlreadyExists, "file", destName, "file already exists")
+	}
+
+	file.FolderID = destFolderID
+	file.Name = destName
+	file.Ext = strings.TrimPrefix(filepath.Ext(destName), ".")
+
+	if err := service.store.PutFile(file.ID, *file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
 }
 
 // GetAll retrieves all files under given folder, applying specific ordering if supplied.
-// An error will be returned if the folder or the user is not found on the system.
+// An error will be returned if the folder or the user is not found on the system,
+// or if the user is not allowed to read the folder.
 func (service *FileServiceImpl) GetAll(username string, folderID int, sortBy string, sortOrder string) ([]models.File, error) {
+	return service.GetAllContext(context.Background(), username, folderID, sortBy, sortOrder)
+}
+
+// GetAllContext is GetAll with a context.
+func (service *FileServiceImpl) GetAllContext(ctx context.Context, username string, folderID int, sortBy string, sortOrder string) ([]models.File, error) {
+	username = actingUser(ctx, username)
 	if !service.userService.Exists(username) {
-		return nil, errors.New("authentication failed")
+		return nil, errUnknownUser(username)
 	}
 
-	if !service.folderService.Exists(folderID) {
-		return nil, errors.New("folder does not exist")
+	folder, err := service.folderService.GetContext(ctx, folderID)
+	if err != nil {
+		return nil, err
 	}
 
-	files := make([]models.File, 0, len(service.files))
-	for _, file := range service.files {
+	if err := service.authorizer.AuthorizeFolder(username, *folder, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	stored := service.store.ListFiles()
+	files := make([]models.File, 0, len(stored))
+	for _, file := range stored {
 		if file.FolderID == folderID {
 			files = append(files, file)
 		}
@@ -133,3 +472,161 @@ func (service *FileServiceImpl) GetAll(username string, folderID int, sortBy str
 
 	return files, nil
 }
+
+// find returns the file with given name under the folder, using case insensitive comparison.
+func (service *FileServiceImpl) find(folderID int, filename string) *models.File {
+	for _, file := range service.store.ListFiles() {
+		if file.FolderID == folderID && strings.EqualFold(file.Name, filename) {
+			return &file
+		}
+	}
+
+	return nil
+}
+
+// findReadable returns the file with given name under the folder, provided the user is allowed to read the folder.
+func (service *FileServiceImpl) findReadable(ctx context.Context, username string, folderID int, filename string) (*models.File, error) {
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	folder, err := service.folderService.GetContext(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(username, *folder, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	file := service.find(folderID, filename)
+	if file == nil {
+		return nil, errNotFound("file", filename)
+	}
+
+	return file, nil
+}
+
+// readContent reads the bytes from content and returns them together with the revision describing them, without a number.
+// A nil content stands for an empty revision.
+// Reading stops once ctx is done, so a large content is not read to the end for nothing.
+func readContent(ctx context.Context, createdBy string, desc string, content io.Reader) (*models.FileVersion, []byte, error) {
+	data := []byte{}
+	if content != nil {
+		var err error
+		if data, err = ioutil.ReadAll(contextReader{ctx: ctx, r: content}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return &models.FileVersion{
+		Desc:      desc,
+		Size:      int64(len(data)),
+		Checksum:  hex.EncodeToString(sum[:]),
+		CreatedAt: time.Now(),
+		CreatedBy: createdBy,
+	}, data, nil
+}
+
+// putFile stores the file, together with the content of its current revision unless data is nil.
+// The content is locked meanwhile, so it cannot be released before the file refers to it.
+func (service *FileServiceImpl) putFile(file models.File, data []byte) error {
+	defer service.locks.lock(blobLockKey(file.Checksum))()
+
+	// Blobs are addressed by checksum so identical contents are stored only once.
+	if data != nil {
+		if err := service.store.PutBlob(file.Checksum, data); err != nil {
+			return err
+		}
+	}
+
+	return service.store.PutFile(file.ID, file)
+}
+
+// addVersion makes the revision the current one of the file, numbered after the latest revision,
+// and drops the oldest revisions beyond the configured limit.
+// The content of the revision is stored unless data is nil, when it is already stored.
+func (service *FileServiceImpl) addVersion(file *models.File, version models.FileVersion, data []byte) (*models.File, error) {
+	versions := versionsOf(*file)
+	version.Number = versions[len(versions)-1].Number + 1
+
+	// The revisions are copied so the slice shared with the store is never written to.
+	file.Versions = append([]models.FileVersion(nil), versions...)
+	setVersion(file, version)
+
+	var dropped []models.FileVersion
+	if service.maxVersions > 0 && len(file.Versions) > service.maxVersions {
+		dropped = file.Versions[:len(file.Versions)-service.maxVersions]
+		file.Versions = append([]models.FileVersion(nil), file.Versions[len(dropped):]...)
+	}
+
+	if err := service.putFile(*file, data); err != nil {
+		return nil, err
+	}
+
+	for _, v := range dropped {
+		if err := releaseBlob(service.store, service.locks, v.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+// setVersion appends the revision to the file and makes it the current one.
+func setVersion(file *models.File, version models.FileVersion) {
+	file.Version = version.Number
+	file.Desc = version.Desc
+	file.Size = version.Size
+	file.Checksum = version.Checksum
+	file.Versions = append(file.Versions, version)
+}
+
+// versionsOf returns the revisions of the file, oldest first.
+// Files stored before revisions were tracked are treated as having their content as the only revision.
+func versionsOf(file models.File) []models.FileVersion {
+	if len(file.Versions) > 0 {
+		return file.Versions
+	}
+
+	number := file.Version
+	if number == 0 {
+		number = 1
+	}
+
+	return []models.FileVersion{{
+		Number:    number,
+		Desc:      file.Desc,
+		Size:      file.Size,
+		Checksum:  file.Checksum,
+		CreatedAt: file.CreatedAt,
+		CreatedBy: file.CreatedBy,
+	}}
+}
+
+// releaseBlob drops the content with given checksum once no file, retained revision or deleted file refers to it anymore.
+// The content is locked meanwhile, so a file about to refer to it waits for it to be stored again.
+func releaseBlob(store Store, locks *lockTable, checksum string) error {
+	defer locks.lock(blobLockKey(checksum))()
+
+	files := store.ListFiles()
+	for _, item := range store.ListTrash() {
+		files = append(files, item.Files...)
+	}
+
+	for _, file := range files {
+		for _, v := range versionsOf(file) {
+			if v.Checksum == checksum {
+				return nil
+			}
+		}
+	}
+
+	return store.DeleteBlob(checksum)
+}
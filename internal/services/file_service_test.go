@@ -1,7 +1,10 @@
 package services
 
 import (
+	"errors"
+	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 	"virtual-file-system/internal/models"
@@ -11,7 +14,7 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 	type fields struct {
 		folders map[int]*models.Folder
 		users   map[string]models.User
-		files   map[string]models.File
+		files   map[int]models.File
 	}
 	type args struct {
 		username  string
@@ -37,9 +40,9 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
+					2: {ID: 2, Name: "1.png", Ext: "png", FolderID: 1002},
 				},
 			},
 			args: args{
@@ -47,8 +50,8 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				folderID: 1002,
 			},
 			want: []models.File{
-				{Name: "1.png", Ext: "png", FolderID: 1002},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002},
+				{ID: 2, Name: "1.png", Ext: "png", FolderID: 1002},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
 			},
 			wantErr: false,
 		},
@@ -63,10 +66,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002},
 				},
 			},
 			args: args{
@@ -74,9 +77,9 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				folderID: 1002,
 			},
 			want: []models.File{
-				{Name: "1.png", Ext: "png", FolderID: 1002},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002},
-				{Name: "2.png", Ext: "png", FolderID: 1002},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002},
 			},
 			wantErr: false,
 		},
@@ -91,10 +94,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002},
 				},
 			},
 			args: args{
@@ -104,9 +107,9 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "dsc",
 			},
 			want: []models.File{
-				{Name: "2.png", Ext: "png", FolderID: 1002},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002},
-				{Name: "1.png", Ext: "png", FolderID: 1002},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002},
 			},
 			wantErr: false,
 		},
@@ -121,10 +124,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -134,9 +137,9 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "asc",
 			},
 			want: []models.File{
-				{Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
 			},
 			wantErr: false,
 		},
@@ -151,11 +154,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -165,10 +168,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "dsc",
 			},
 			want: []models.File{
-				{Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-				{Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+				{ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
 			},
 			wantErr: false,
 		},
@@ -183,11 +186,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -197,10 +200,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "asc",
 			},
 			want: []models.File{
-				{Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-				{Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-				{Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+				{ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 			},
 			wantErr: false,
 		},
@@ -215,11 +218,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -229,10 +232,10 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "dsc",
 			},
 			want: []models.File{
-				{Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-				{Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
-				{Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-				{Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+				{ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+				{ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				{ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+				{ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
 			},
 			wantErr: false,
 		},
@@ -247,11 +250,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -271,11 +274,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -296,11 +299,11 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 					"luke": {Name: "Luke"},
 					"mark": {Name: "Mark"},
 				},
-				files: map[string]models.File{
-					"1.tc":  {Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					"2.png": {Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
-					"1.png": {Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
-					"2.tc":  {Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
+					2: {ID: 2, Name: "2.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 23, 0, 0, 0, 0, time.UTC)},
+					3: {ID: 3, Name: "1.png", Ext: "png", FolderID: 1002, CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					4: {ID: 4, Name: "2.tc", Ext: "tc", FolderID: 1002, CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
 				},
 			},
 			args: args{
@@ -312,14 +315,17 @@ func TestFileServiceImpl_GetAll(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   tt.fields.users,
+				folders: tt.fields.folders,
+				files:   tt.fields.files,
+			}
+			authorizer := &AuthorizerImpl{store: store}
 			service := &FileServiceImpl{
-				files: tt.fields.files,
-				userService: &UserServiceImpl{
-					users: tt.fields.users,
-				},
-				folderService: &FolderServiceImpl{
-					folders: tt.fields.folders,
-				},
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
 			}
 			got, err := service.GetAll(tt.args.username, tt.args.folderID, tt.args.sortBy, tt.args.sortOrder)
 			if (err != nil) != tt.wantErr {
@@ -337,7 +343,7 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 	type fields struct {
 		folders map[int]*models.Folder
 		users   map[string]models.User
-		files   map[string]models.File
+		files   map[int]models.File
 	}
 	type args struct {
 		deletedBy string
@@ -355,7 +361,7 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{"1.tc": {Name: "1.tc", Ext: "tc", FolderID: 1001}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
 			},
 			args: args{
 				deletedBy: "Luke",
@@ -369,7 +375,7 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{"1.tc": {Name: "1.tc", Ext: "tc", FolderID: 1001}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
 			},
 			args: args{
 				deletedBy: "Luke",
@@ -383,7 +389,7 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{"1.tc": {Name: "1.tc", Ext: "tc", FolderID: 1001}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
 			},
 			args: args{
 				deletedBy: "Luke",
@@ -397,7 +403,7 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{"1.tc": {Name: "1.tc", Ext: "tc", FolderID: 1001}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
 			},
 			args: args{
 				deletedBy: "mark",
@@ -406,13 +412,48 @@ func TestFileServiceImpl_Delete(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "05. it should return error if file is under another folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}, 1002: {Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002}},
+			},
+			args: args{
+				deletedBy: "Luke",
+				folderID:  1001,
+				filename:  "1.tc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "06. it should return error if user is not allowed to write the file.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001, CreatedBy: "Luke"}},
+			},
+			args: args{
+				deletedBy: "Mark",
+				folderID:  1001,
+				filename:  "1.tc",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   tt.fields.users,
+				folders: tt.fields.folders,
+				files:   tt.fields.files,
+			}
+			authorizer := &AuthorizerImpl{store: store}
 			service := &FileServiceImpl{
-				files:         tt.fields.files,
-				userService:   &UserServiceImpl{users: tt.fields.users},
-				folderService: &FolderServiceImpl{folders: tt.fields.folders},
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
 			}
 			if err := service.Delete(tt.args.deletedBy, tt.args.folderID, tt.args.filename); (err != nil) != tt.wantErr {
 				t.Errorf("FileServiceImpl.Delete() error = %v, wantErr %v", err, tt.wantErr)
@@ -425,13 +466,14 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 	type fields struct {
 		folders map[int]*models.Folder
 		users   map[string]models.User
-		files   map[string]models.File
+		files   map[int]models.File
 	}
 	type args struct {
 		createdBy string
 		folderID  int
 		filename  string
 		desc      string
+		content   string
 	}
 	tests := []struct {
 		name    string
@@ -444,13 +486,14 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{},
+				files:   map[int]models.File{},
 			},
 			args: args{
 				createdBy: "Luke",
 				folderID:  1001,
 				filename:  "1.tc",
 				desc:      "first test case for a company",
+				content:   "assert 1 + 1 == 2",
 			},
 			wantErr: false,
 		},
@@ -459,7 +502,7 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{},
+				files:   map[int]models.File{},
 			},
 			args: args{
 				createdBy: "Luke",
@@ -474,7 +517,7 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{},
+				files:   map[int]models.File{},
 			},
 			args: args{
 				createdBy: "mark",
@@ -489,7 +532,7 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
-				files:   map[string]models.File{"1.tc": {Name: "1.tc", Ext: "tc", FolderID: 1001}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
 			},
 			args: args{
 				createdBy: "Luke",
@@ -499,17 +542,487 @@ func TestFileServiceImpl_Upload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "05. it should upload file with a name used in another folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}, 1002: {Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{2: {ID: 2, Name: "1.tc", Ext: "tc", FolderID: 1002}},
+			},
+			args: args{
+				createdBy: "Luke",
+				folderID:  1001,
+				filename:  "1.tc",
+			},
+			wantErr: false,
+		},
+		{
+			name: "06. it should return error if file already exist using case-insensitive comparison.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001}},
+			},
+			args: args{
+				createdBy: "Luke",
+				folderID:  1001,
+				filename:  "1.TC",
+			},
+			wantErr: true,
+		},
+		{
+			name: "07. it should return error if user is not allowed to write the folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				files:   map[int]models.File{},
+			},
+			args: args{
+				createdBy: "Mark",
+				folderID:  1001,
+				filename:  "1.tc",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   tt.fields.users,
+				folders: tt.fields.folders,
+				files:   tt.fields.files,
+			}
+			authorizer := &AuthorizerImpl{store: store}
 			service := &FileServiceImpl{
-				files:         tt.fields.files,
-				userService:   &UserServiceImpl{users: tt.fields.users},
-				folderService: &FolderServiceImpl{folders: tt.fields.folders},
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
 			}
-			if err := service.Upload(tt.args.createdBy, tt.args.folderID, tt.args.filename, tt.args.desc); (err != nil) != tt.wantErr {
+			_, err := service.Upload(tt.args.createdBy, tt.args.folderID, tt.args.filename, tt.args.desc, strings.NewReader(tt.args.content))
+			if (err != nil) != tt.wantErr {
 				t.Errorf("FileServiceImpl.Upload() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				file := store.files[1]
+				if file.Size != int64(len(tt.args.content)) {
+					t.Errorf("FileServiceImpl.Upload() size = %v, want %v", file.Size, len(tt.args.content))
+				}
+				if string(store.blobs[file.Checksum]) != tt.args.content {
+					t.Errorf("FileServiceImpl.Upload() content = %q, want %q", store.blobs[file.Checksum], tt.args.content)
+				}
 			}
 		})
 	}
 }
+
+func TestFileServiceImpl_Open(t *testing.T) {
+	type fields struct {
+		folders map[int]*models.Folder
+		users   map[string]models.User
+		files   map[int]models.File
+		blobs   map[string][]byte
+	}
+	type args struct {
+		username string
+		folderID int
+		filename string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "01. it should return the content of the file.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001, Size: 5, Checksum: "abc"}},
+				blobs:   map[string][]byte{"abc": []byte("hello")},
+			},
+			args: args{
+				username: "Luke",
+				folderID: 1001,
+				filename: "1.tc",
+			},
+			want:    "hello",
+			wantErr: false,
+		},
+		{
+			name: "02. it should return error if file is under another folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}, 1002: {Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1002, Size: 5, Checksum: "abc"}},
+				blobs:   map[string][]byte{"abc": []byte("hello")},
+			},
+			args: args{
+				username: "Luke",
+				folderID: 1001,
+				filename: "1.tc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "03. it should return error if user not found.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				files:   map[int]models.File{1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001, Size: 5, Checksum: "abc"}},
+				blobs:   map[string][]byte{"abc": []byte("hello")},
+			},
+			args: args{
+				username: "mark",
+				folderID: 1001,
+				filename: "1.tc",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   tt.fields.users,
+				folders: tt.fields.folders,
+				files:   tt.fields.files,
+				blobs:   tt.fields.blobs,
+			}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &FileServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+			}
+			rc, err := service.Open(tt.args.username, tt.args.folderID, tt.args.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FileServiceImpl.Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer rc.Close()
+
+			got, _ := ioutil.ReadAll(rc)
+			if string(got) != tt.want {
+				t.Errorf("FileServiceImpl.Open() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileServiceImpl_UploadVersion(t *testing.T) {
+	type args struct {
+		createdBy string
+		contents  []string
+	}
+	tests := []struct {
+		name        string
+		maxVersions int
+		args        args
+		want        []int
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name: "01. it should keep every revision without a limit.",
+			args: args{
+				createdBy: "Luke",
+				contents:  []string{"v1", "v2", "v3"},
+			},
+			want:        []int{1, 2, 3},
+			wantContent: "v3",
+			wantErr:     false,
+		},
+		{
+			name:        "02. it should drop the oldest revisions beyond the limit.",
+			maxVersions: 2,
+			args: args{
+				createdBy: "Luke",
+				contents:  []string{"v1", "v2", "v3"},
+			},
+			want:        []int{2, 3},
+			wantContent: "v3",
+			wantErr:     false,
+		},
+		{
+			name: "03. it should return error if user is not allowed to write the file.",
+			args: args{
+				createdBy: "Mark",
+				contents:  []string{"v1", "v2"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+				files:   map[int]models.File{},
+				blobs:   map[string][]byte{},
+			}
+			store.grants = map[string]models.Grant{makeGrantKey(1001, "Mark"): {FolderID: 1001, Grantee: "Mark", Access: models.AccessReadOnly}}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &FileServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+				maxVersions:   tt.maxVersions,
+			}
+
+			if _, err := service.Upload("Luke", 1001, "1.tc", "", strings.NewReader(tt.args.contents[0])); err != nil {
+				t.Fatalf("FileServiceImpl.Upload() error = %v", err)
+			}
+
+			var err error
+			for _, content := range tt.args.contents[1:] {
+				if _, err = service.UploadVersion(tt.args.createdBy, 1001, "1.tc", "", strings.NewReader(content)); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FileServiceImpl.UploadVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			versions, err := service.ListVersions("Luke", 1001, "1.tc")
+			if err != nil {
+				t.Fatalf("FileServiceImpl.ListVersions() error = %v", err)
+			}
+
+			var got []int
+			for _, v := range versions {
+				got = append(got, v.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileServiceImpl.ListVersions() = %v, want %v", got, tt.want)
+			}
+
+			if len(store.blobs) != len(tt.want) {
+				t.Errorf("FileServiceImpl.UploadVersion() retained %d blobs, want %d", len(store.blobs), len(tt.want))
+			}
+
+			rc, _ := service.Open("Luke", 1001, "1.tc")
+			defer rc.Close()
+			if content, _ := ioutil.ReadAll(rc); string(content) != tt.wantContent {
+				t.Errorf("FileServiceImpl.Open() = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestFileServiceImpl_RestoreVersion(t *testing.T) {
+	versions := []models.FileVersion{
+		{Number: 1, Size: 2, Checksum: "c1", CreatedBy: "Luke"},
+		{Number: 2, Size: 3, Checksum: "c2", CreatedBy: "Luke"},
+	}
+	tests := []struct {
+		name        string
+		number      int
+		wantVersion int
+		wantSum     string
+		wantErr     bool
+	}{
+		{
+			name:        "01. it should restore the content of the revision as a new revision.",
+			number:      1,
+			wantVersion: 3,
+			wantSum:     "c1",
+			wantErr:     false,
+		},
+		{
+			name:    "02. it should return error if the revision does not exist.",
+			number:  5,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", FolderID: 1001, Size: 3, Checksum: "c2", CreatedBy: "Luke", Version: 2, Versions: versions},
+				},
+				blobs: map[string][]byte{"c1": []byte("v1"), "c2": []byte("v22")},
+			}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &FileServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+			}
+
+			got, err := service.RestoreVersion("Luke", 1001, "1.tc", tt.number)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FileServiceImpl.RestoreVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			if got.Version != tt.wantVersion || got.Checksum != tt.wantSum || len(got.Versions) != 3 {
+				t.Errorf("FileServiceImpl.RestoreVersion() = %+v, want version %d with checksum %s", got, tt.wantVersion, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestFileServiceImpl_Move(t *testing.T) {
+	type args struct {
+		folderID     int
+		filename     string
+		destFolderID int
+		destName     string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "01. it should move the file into another folder with a new name.",
+			args: args{
+				folderID:     1001,
+				filename:     "1.tc",
+				destFolderID: 1002,
+				destName:     "2.png",
+			},
+			wantErr: false,
+		},
+		{
+			name: "02. it should rename the file within its folder.",
+			args: args{
+				folderID:     1001,
+				filename:     "1.tc",
+				destFolderID: 1001,
+				destName:     "1.TC",
+			},
+			wantErr: false,
+		},
+		{
+			name: "03. it should return error if the name exists in the destination folder.",
+			args: args{
+				folderID:     1001,
+				filename:     "1.tc",
+				destFolderID: 1002,
+				destName:     "1.png",
+			},
+			wantErr: true,
+		},
+		{
+			name: "04. it should return error if the destination folder does not exist.",
+			args: args{
+				folderID:     1001,
+				filename:     "1.tc",
+				destFolderID: 9999,
+				destName:     "1.tc",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users: map[string]models.User{"luke": {Name: "Luke"}},
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, Name: "Testing", CreatedBy: "Luke"},
+				},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", Ext: "tc", FolderID: 1001},
+					2: {ID: 2, Name: "1.png", Ext: "png", FolderID: 1002},
+				},
+			}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &FileServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+			}
+			got, err := service.Move("Luke", tt.args.folderID, tt.args.filename, tt.args.destFolderID, tt.args.destName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FileServiceImpl.Move() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && (got.ID != 1 || store.files[1].FolderID != tt.args.destFolderID || store.files[1].Name != tt.args.destName) {
+				t.Errorf("FileServiceImpl.Move() = %+v", store.files[1])
+			}
+		})
+	}
+}
+
+func TestFileServiceImpl_Get(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		prepare  func(factory *Factory, workID int)
+		wantErr  error
+	}{
+		{
+			name:     "01. it should return the file to the owner of the folder.",
+			username: "luke",
+		},
+		{
+			name:     "02. it should return the file to the uploader who can still write the folder.",
+			username: "mark",
+		},
+		{
+			name:     "03. it should return error to the uploader once the folder is no longer shared with them.",
+			username: "mark",
+			prepare: func(factory *Factory, workID int) {
+				factory.GetShareService().Unshare(workID, "mark", "luke")
+			},
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			factory.GetUserService().Register("luke", "")
+			factory.GetUserService().Register("mark", "")
+			work, _ := factory.GetFolderService().Create("Work", "luke", "")
+			factory.GetShareService().Share(work.ID, "mark", "rw", "luke")
+			file, err := factory.GetFileService().Upload("mark", work.ID, "1.tc", "", strings.NewReader("hello"))
+			if err != nil {
+				t.Fatalf("FileServiceImpl.Upload() error = %v", err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(factory, work.ID)
+			}
+
+			if _, err := factory.GetFileService().Get(tt.username, file.ID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("FileServiceImpl.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileServiceImpl_Delete_ReadOnlyUploader(t *testing.T) {
+	factory := &Factory{}
+	factory.GetUserService().Register("luke", "")
+	factory.GetUserService().Register("mark", "")
+	work, _ := factory.GetFolderService().Create("Work", "luke", "")
+	factory.GetShareService().Share(work.ID, "mark", "rw", "luke")
+	factory.GetFileService().Upload("mark", work.ID, "1.tc", "", strings.NewReader("hello"))
+	factory.GetShareService().Share(work.ID, "mark", "ro", "luke")
+
+	if err := factory.GetFileService().Delete("mark", work.ID, "1.tc"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("FileServiceImpl.Delete() error = %v, want %v", err, ErrPermissionDenied)
+	}
+}
@@ -1,108 +1,273 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"virtual-file-system/internal/models"
 )
 
 // FolderService is responsible for CRUD operations against a folder
+//
+// The methods ending in Context give up once the context is done, returning its error,
+// and act on behalf of the user carried by the context, see WithUser, when given an empty user name.
 type FolderService interface {
 	// Create adds a folder to the system.
+	// The name may be a path, e.g. "/work/reports/2026", to create the folder under an existing parent,
+	// otherwise the folder is created at the root of `createdBy`.
 	// If the given `createdBy` does not match existing users in the system, an error is returned.
-	// If the given folder name already exists in the system, an error is returned.
+	// If the given folder name already exists under the same parent, an error is returned.
 	Create(name string, createdBy string, desc string) (*models.Folder, error)
 
-	// Delete removes a folder with given id from the system.
-	// If the given `deletedBy` does not match existing users in the system, an error is returned.
+	// CreateContext is Create with a context.
+	CreateContext(ctx context.Context, name string, createdBy string, desc string) (*models.Folder, error)
+
+	// Delete moves a folder with given id to the trash of `deletedBy`.
+	// A folder holding subfolders or files is only deleted if recursive is set, together with all of its content at once.
+	// If the given `deletedBy` does not match existing users in the system or does not own the folder, an error is returned.
 	// If the given id does not match existing folders in the system, an error is returned.
-	Delete(id int, deletedBy string) error
+	// If the folder is not empty and recursive is not set, an error is returned.
+	Delete(id int, deletedBy string, recursive bool) error
+
+	// DeleteContext is Delete with a context.
+	DeleteContext(ctx context.Context, id int, deletedBy string, recursive bool) error
 
-	// GetAll retrives all folders in the system.
+	// GetAll retrives all folders in the system that the user can read, including the ones shared with the user.
 	// If the sorting conditions were supplied, they will be applied as well.
 	// TODO Should empty folders consider an error? Currently it is not.
 	// If the given `username` does not match existing users in the system, an error is returned.
 	GetAll(username string, sortBy string, sortOrder string) ([]models.Folder, error)
 
+	// GetAllContext is GetAll with a context.
+	GetAllContext(ctx context.Context, username string, sortBy string, sortOrder string) ([]models.Folder, error)
+
 	// Rename gives the folder with given id a new name.
-	// If the given `renamedBy` does not match existing users or the original owner, an error is returned.
+	// If the given `renamedBy` does not match existing users or is not allowed to write the folder, an error is returned.
 	// If the given id does not match existing folders in the system, an error is returned.
+	// If the given name already exists under the same parent, an error is returned.
 	Rename(id int, name string, renamedBy string) error
 
+	// RenameContext is Rename with a context.
+	RenameContext(ctx context.Context, id int, name string, renamedBy string) error
+
+	// Move puts the folder with given id under the folder with ID parentID, or at the root of its owner if parentID is 0,
+	// giving it the new name at the same time.
+	// If the given `movedBy` does not match existing users or is not allowed to write both the folder and the new parent, an error is returned.
+	// If the new parent is the folder itself or one of its subfolders, an error is returned.
+	// If the given name already exists under the new parent, an error is returned.
+	Move(id int, parentID int, name string, movedBy string) error
+
+	// MoveContext is Move with a context.
+	MoveContext(ctx context.Context, id int, parentID int, name string, movedBy string) error
+
 	// Exists returns true if the given folder id exists in the internal folder storage.
 	Exists(id int) bool
 
+	// ExistsContext is Exists with a context.
+	ExistsContext(ctx context.Context, id int) (bool, error)
+
 	// Get returns the folder with given ID.
 	// If no such folder exists, an error is returned.
 	Get(id int) (*models.Folder, error)
+
+	// GetContext is Get with a context.
+	GetContext(ctx context.Context, id int) (*models.Folder, error)
+
+	// Resolve returns the folder referred to by either its ID or its path.
+	// Absolute paths such as "/work/reports" start from the root of `username`,
+	// relative paths start from the folder with the ID in their first segment, e.g. "1001/reports".
+	// If the given `username` does not match existing users in the system or cannot read the folder, an error is returned.
+	// If no such folder exists, an error is returned.
+	Resolve(username string, ref string) (*models.Folder, error)
+
+	// ResolveContext is Resolve with a context.
+	ResolveContext(ctx context.Context, username string, ref string) (*models.Folder, error)
+
+	// Path returns the absolute path of the folder with given ID, starting from the root of its owner.
+	// If no such folder exists, an error is returned.
+	Path(id int) (string, error)
+
+	// PathContext is Path with a context.
+	PathContext(ctx context.Context, id int) (string, error)
 }
 
 // FolderServiceImpl is the implementation of the FolderService interface
 type FolderServiceImpl struct {
-	folders     map[int]*models.Folder
+	store       Store
 	userService UserService
-	nextKey     int
+	authorizer  Authorizer
+	locks       *lockTable
 }
 
 // Create adds a folder to the system.
+// The name may be a path, e.g. "/work/reports/2026", to create the folder under an existing parent,
+// otherwise the folder is created at the root of `createdBy`.
 // If the given `createdBy` does not match existing users in the system, an error is returned.
-// If the given folder name already exists in the system, an error is returned.
+// If the given folder name already exists under the same parent, an error is returned.
 func (service *FolderServiceImpl) Create(name string, createdBy string, desc string) (*models.Folder, error) {
+	return service.CreateContext(context.Background(), name, createdBy, desc)
+}
+
+// CreateContext is Create with a context.
+func (service *FolderServiceImpl) CreateContext(ctx context.Context, name string, createdBy string, desc string) (*models.Folder, error) {
+	createdBy = actingUser(ctx, createdBy)
 	if !service.userService.Exists(createdBy) {
-		return nil, errors.New("unknown user")
+		return nil, errUnknownUser(createdBy)
+	}
+
+	defer service.locks.rlockTree()()
+
+	var parentID int
+	if i := strings.LastIndex(name, pathSeparator); i >= 0 {
+		if dir := name[:i]; strings.Trim(dir, pathSeparator) != "" {
+			parent, err := service.ResolveContext(ctx, createdBy, dir)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := service.authorizer.AuthorizeFolder(createdBy, *parent, PermissionWrite); err != nil {
+				return nil, err
+			}
+
+			parentID = parent.ID
+		}
+		name = name[i+1:]
+	}
+
+	if err := validateFolderName(name); err != nil {
+		return nil, err
+	}
+
+	defer service.locks.lock(childrenLockKey(parentID, createdBy))()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	if service.isNameAlreadyExist(name) {
-		return nil, errors.New("folder name already exists")
+	if service.isNameAlreadyExist(parentID, createdBy, name, 0) {
+		return nil, newError(ErrAlreadyExists, "folder", name, "folder name already exists")
+	}
+
+	key, err := service.store.NextFolderID()
+	if err != nil {
+		return nil, err
 	}
 
-	key := service.makeNewKey()
 	folder := &models.Folder{
 		ID:          key,
+		ParentID:    parentID,
 		Name:        name,
 		Description: desc,
 		CreatedBy:   createdBy,
 		CreatedAt:   time.Now(),
 	}
 
-	service.folders[key] = folder
-	service.nextKey++
+	if err := service.store.PutFolder(key, *folder); err != nil {
+		return nil, err
+	}
 
 	return folder, nil
 }
 
-// Delete removes a folder with given id from the system.
-// If the given `deletedBy` does not match existing users in the system, an error is returned.
+// Delete moves a folder with given id to the trash of `deletedBy`.
+// A folder holding subfolders or files is only deleted if recursive is set, together with all of its content at once.
+// If the given `deletedBy` does not match existing users in the system or does not own the folder, an error is returned.
 // If the given id does not match existing folders in the system, an error is returned.
-func (service *FolderServiceImpl) Delete(id int, deletedBy string) error {
+// If the folder is not empty and recursive is not set, an error is returned.
+func (service *FolderServiceImpl) Delete(id int, deletedBy string, recursive bool) error {
+	return service.DeleteContext(context.Background(), id, deletedBy, recursive)
+}
+
+// DeleteContext is Delete with a context.
+func (service *FolderServiceImpl) DeleteContext(ctx context.Context, id int, deletedBy string, recursive bool) error {
+	deletedBy = actingUser(ctx, deletedBy)
 	if !service.userService.Exists(deletedBy) {
-		return errors.New("user does not exist")
+		return errUnknownUser(deletedBy)
 	}
 
-	f, err := service.Get(id)
+	// The whole subtree goes away, so nothing may change in the hierarchy meanwhile.
+	defer service.locks.lockTree()()
+
+	f, err := service.GetContext(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if !strings.EqualFold(f.CreatedBy, deletedBy) {
-		return errors.New("folder owner does not match")
+	if err := service.authorizer.AuthorizeFolder(deletedBy, *f, PermissionOwner); err != nil {
+		return err
 	}
 
-	delete(service.folders, id)
+	path, err := service.PathContext(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	folders, err := service.subtree(ctx, *f)
+	if err != nil {
+		return err
+	}
+
+	item := models.TrashItem{
+		Path:      path,
+		Folders:   folders,
+		DeletedBy: deletedBy,
+	}
+
+	deleted := make(map[int]bool, len(item.Folders))
+	for _, folder := range item.Folders {
+		deleted[folder.ID] = true
+	}
+
+	for _, file := range service.store.ListFiles() {
+		if deleted[file.FolderID] {
+			item.Files = append(item.Files, file)
+		}
+	}
+
+	if !recursive && (len(item.Folders) > 1 || len(item.Files) > 0) {
+		return newError(ErrConflict, "folder", id, "folder is not empty, use --recursive to delete it with its content")
+	}
+
+	for _, grant := range service.store.ListGrants() {
+		if deleted[grant.FolderID] {
+			item.Grants = append(item.Grants, grant)
+		}
+	}
+
+	// The content is collected by now, past this point the folder goes to the trash as a whole or not at all.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return moveToTrash(service.store, item)
 }
 
-// GetAll retrives all folders in the system.
+// GetAll retrives all folders in the system that the user can read, including the ones shared with the user.
 // If the sorting conditions were supplied, they will be applied as well.
 // TODO Should empty folders consider an error? Currently it is not.
 // If the given `username` does not match existing users in the system, an error is returned.
 func (service *FolderServiceImpl) GetAll(username string, sortBy string, sortOrder string) ([]models.Folder, error) {
-	folders := make([]models.Folder, 0, len(service.folders))
-	for _, value := range service.folders {
-		folders = append(folders, *value)
+	return service.GetAllContext(context.Background(), username, sortBy, sortOrder)
+}
+
+// GetAllContext is GetAll with a context.
+func (service *FolderServiceImpl) GetAllContext(ctx context.Context, username string, sortBy string, sortOrder string) ([]models.Folder, error) {
+	username = actingUser(ctx, username)
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	folders := make([]models.Folder, 0)
+	for _, f := range service.store.ListFolders() {
+		if service.authorizer.FolderPermission(username, f) >= PermissionRead {
+			folders = append(folders, f)
+		}
 	}
 
 	if sortBy == "sort_name" {
@@ -135,65 +300,323 @@ func (service *FolderServiceImpl) GetAll(username string, sortBy string, sortOrd
 }
 
 // Rename gives the folder with given id a new name.
-// If the given `renamedBy` does not match existing users or the original owner, an error is returned.
+// If the given `renamedBy` does not match existing users or is not allowed to write the folder, an error is returned.
 // If the given id does not match existing folders in the system, an error is returned.
+// If the given name already exists under the same parent, an error is returned.
 func (service *FolderServiceImpl) Rename(id int, name string, renamedBy string) error {
+	return service.RenameContext(context.Background(), id, name, renamedBy)
+}
+
+// RenameContext is Rename with a context.
+func (service *FolderServiceImpl) RenameContext(ctx context.Context, id int, name string, renamedBy string) error {
+	renamedBy = actingUser(ctx, renamedBy)
 	if !service.userService.Exists(renamedBy) {
-		return errors.New("user does not exist")
+		return errUnknownUser(renamedBy)
 	}
 
-	f, err := service.Get(id)
+	defer service.locks.rlockTree()()
+
+	f, err := service.GetContext(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	// The folder is read again once its siblings are locked, as a concurrent rename may have changed it.
+	defer service.locks.lock(childrenLockKey(f.ParentID, f.CreatedBy))()
+
+	if f, err = service.GetContext(ctx, id); err != nil {
+		return err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(renamedBy, *f, PermissionWrite); err != nil {
+		return err
+	}
+
+	if err := validateFolderName(name); err != nil {
+		return err
+	}
+
+	if service.isNameAlreadyExist(f.ParentID, f.CreatedBy, name, id) {
+		return newError(ErrAlreadyExists, "folder", name, "folder name already exists")
+	}
+
 	f.Name = name
 
-	return nil
+	return service.store.PutFolder(id, *f)
+}
+
+// Move puts the folder with given id under the folder with ID parentID, or at the root of its owner if parentID is 0,
+// giving it the new name at the same time.
+// If the given `movedBy` does not match existing users or is not allowed to write both the folder and the new parent, an error is returned.
+// If the new parent is the folder itself or one of its subfolders, an error is returned.
+// If the given name already exists under the new parent, an error is returned.
+func (service *FolderServiceImpl) Move(id int, parentID int, name string, movedBy string) error {
+	return service.MoveContext(context.Background(), id, parentID, name, movedBy)
+}
+
+// MoveContext is Move with a context.
+func (service *FolderServiceImpl) MoveContext(ctx context.Context, id int, parentID int, name string, movedBy string) error {
+	movedBy = actingUser(ctx, movedBy)
+	if !service.userService.Exists(movedBy) {
+		return errUnknownUser(movedBy)
+	}
+
+	// Moving changes the shape of the hierarchy, checked against cycles below.
+	defer service.locks.lockTree()()
+
+	f, err := service.GetContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(movedBy, *f, PermissionWrite); err != nil {
+		return err
+	}
+
+	if parentID != 0 {
+		parent, err := service.GetContext(ctx, parentID)
+		if err != nil {
+			return err
+		}
+
+		if err := service.authorizer.AuthorizeFolder(movedBy, *parent, PermissionWrite); err != nil {
+			return err
+		}
+
+		folders, err := service.subtree(ctx, *f)
+		if err != nil {
+			return err
+		}
+
+		for _, folder := range folders {
+			if folder.ID == parentID {
+				return newError(ErrInvalid, "folder", parentID, "folder cannot be moved into itself")
+			}
+		}
+	} else if !strings.EqualFold(f.CreatedBy, movedBy) {
+		return newError(ErrPermissionDenied, "folder", id, "permission denied: only the owner can move the folder to the root")
+	}
+
+	if err := validateFolderName(name); err != nil {
+		return err
+	}
+
+	if service.isNameAlreadyExist(parentID, f.CreatedBy, name, id) {
+		return newError(ErrAlreadyExists, "folder", name, "folder name already exists")
+	}
+
+	f.ParentID = parentID
+	f.Name = name
+
+	return service.store.PutFolder(id, *f)
 }
 
 // Exists returns true if the given folder id exists in the internal folder storage.
 func (service *FolderServiceImpl) Exists(id int) bool {
-	_, exists := service.folders[id]
+	_, exists := service.store.GetFolder(id)
 
 	return exists
 }
 
+// ExistsContext is Exists with a context.
+func (service *FolderServiceImpl) ExistsContext(ctx context.Context, id int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return service.Exists(id), nil
+}
+
 // Get returns the folder with given ID.
 // If no such folder exists, an error is returned.
 func (service *FolderServiceImpl) Get(id int) (*models.Folder, error) {
-	f, exists := service.folders[id]
+	return service.GetContext(context.Background(), id)
+}
+
+// GetContext is Get with a context.
+func (service *FolderServiceImpl) GetContext(ctx context.Context, id int) (*models.Folder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, exists := service.store.GetFolder(id)
 	if !exists {
-		return nil, errors.New("folder does not exist")
+		return nil, errNotFound("folder", id)
 	}
 
-	return f, nil
+	return &f, nil
+}
+
+// Resolve returns the folder referred to by either its ID or its path.
+// Absolute paths such as "/work/reports" start from the root of `username`,
+// relative paths start from the folder with the ID in their first segment, e.g. "1001/reports".
+// If the given `username` does not match existing users in the system or cannot read the folder, an error is returned.
+// If no such folder exists, an error is returned.
+func (service *FolderServiceImpl) Resolve(username string, ref string) (*models.Folder, error) {
+	return service.ResolveContext(context.Background(), username, ref)
 }
 
-func (service *FolderServiceImpl) makeNewKey() int {
-	size := len(service.folders)
-	if size == 0 {
-		return service.nextKey
+// ResolveContext is Resolve with a context.
+func (service *FolderServiceImpl) ResolveContext(ctx context.Context, username string, ref string) (*models.Folder, error) {
+	username = actingUser(ctx, username)
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
 	}
 
-	sortedKeys := service.getSortedKeys()
-	return sortedKeys[size-1]
+	segments := strings.Split(ref, pathSeparator)
+
+	var current *models.Folder
+	if !strings.HasPrefix(ref, pathSeparator) {
+		if id, err := strconv.Atoi(segments[0]); err == nil {
+			f, err := service.GetContext(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+
+			current = f
+			segments = segments[1:]
+		}
+	}
+
+	for _, segment := range segments {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			if current != nil && current.ParentID != 0 {
+				parent, err := service.GetContext(ctx, current.ParentID)
+				if err != nil {
+					return nil, err
+				}
+				current = parent
+			} else {
+				current = nil
+			}
+			continue
+		}
+
+		var next *models.Folder
+		if current == nil {
+			next = service.findChild(0, username, segment)
+		} else {
+			next = service.findChild(current.ID, current.CreatedBy, segment)
+		}
+
+		if next == nil {
+			return nil, errNotFound("folder", ref)
+		}
+		current = next
+	}
+
+	if current == nil {
+		return nil, newError(ErrInvalid, "folder", ref, "the root is not a folder")
+	}
+
+	if err := service.authorizer.AuthorizeFolder(username, *current, PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// Path returns the absolute path of the folder with given ID, starting from the root of its owner.
+// If no such folder exists, an error is returned.
+func (service *FolderServiceImpl) Path(id int) (string, error) {
+	return service.PathContext(context.Background(), id)
 }
 
-func (service *FolderServiceImpl) getSortedKeys() []int {
-	keys := make([]int, 0, len(service.folders))
-	for k := range service.folders {
-		keys = append(keys, k)
+// PathContext is Path with a context.
+func (service *FolderServiceImpl) PathContext(ctx context.Context, id int) (string, error) {
+	var names []string
+
+	// The visited set guards against cycles in a corrupted hierarchy.
+	visited := make(map[int]bool)
+	for id != 0 && !visited[id] {
+		visited[id] = true
+
+		f, err := service.GetContext(ctx, id)
+		if err != nil {
+			return "", err
+		}
+
+		names = append([]string{f.Name}, names...)
+		id = f.ParentID
 	}
-	return sort.IntSlice(keys)
+
+	return pathSeparator + strings.Join(names, pathSeparator), nil
 }
 
-func (service *FolderServiceImpl) isNameAlreadyExist(name string) bool {
-	for _, v := range service.folders {
-		if strings.EqualFold(v.Name, name) {
-			return true
+// subtree returns the folder together with all the folders below it, parents first.
+// It gives up once ctx is done, as the hierarchy may be deep.
+func (service *FolderServiceImpl) subtree(ctx context.Context, folder models.Folder) ([]models.Folder, error) {
+	folders := []models.Folder{folder}
+
+	// The visited set guards against cycles in a corrupted hierarchy.
+	visited := map[int]bool{folder.ID: true}
+	for i := 0; i < len(folders); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, child := range service.children(folders[i].ID) {
+			if !visited[child.ID] {
+				visited[child.ID] = true
+				folders = append(folders, child)
+			}
 		}
 	}
 
-	return false
+	return folders, nil
+}
+
+// children returns the folders directly under the folder with given ID.
+func (service *FolderServiceImpl) children(id int) []models.Folder {
+	var children []models.Folder
+	for _, v := range service.store.ListFolders() {
+		if v.ParentID == id {
+			children = append(children, v)
+		}
+	}
+
+	return children
+}
+
+// findChild returns the folder with given name under the parent, using case insensitive comparison.
+// Folders at the root are looked up among the ones owned by `owner`.
+func (service *FolderServiceImpl) findChild(parentID int, owner string, name string) *models.Folder {
+	for _, v := range service.store.ListFolders() {
+		if v.ParentID != parentID || !strings.EqualFold(v.Name, name) {
+			continue
+		}
+
+		if parentID == 0 && !strings.EqualFold(v.CreatedBy, owner) {
+			continue
+		}
+
+		return &v
+	}
+
+	return nil
+}
+
+// isNameAlreadyExist reports whether a sibling other than the folder with ID `except` already uses the name.
+func (service *FolderServiceImpl) isNameAlreadyExist(parentID int, owner string, name string, except int) bool {
+	f := service.findChild(parentID, owner, name)
+
+	return f != nil && f.ID != except
+}
+
+// pathSeparator separates the folder names in a path.
+const pathSeparator = "/"
+
+func validateFolderName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newError(ErrInvalid, "folder", name, "folder name should not be empty")
+	}
+
+	if strings.Contains(name, pathSeparator) || name == "." || name == ".." {
+		return newError(ErrInvalid, "folder", name, "folder name should not contain \"/\" or be \".\" or \"..\"")
+	}
+
+	return nil
 }
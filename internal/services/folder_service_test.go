@@ -67,7 +67,7 @@ func TestFolderServiceImpl_Create(t *testing.T) {
 			name: "03. it should return error when given folder name already exist.",
 			fields: fields{
 				folders: map[int]*models.Folder{
-					1001: {Name: "Work"},
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
 				},
 				users: map[string]models.User{
 					"luke": {Name: "Luke"},
@@ -85,7 +85,7 @@ func TestFolderServiceImpl_Create(t *testing.T) {
 			name: "04. it should return error when given folder name already exist using case-insensitive comparison.",
 			fields: fields{
 				folders: map[int]*models.Folder{
-					1001: {Name: "Work"},
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
 				},
 				users: map[string]models.User{
 					"luke": {Name: "Luke"},
@@ -99,15 +99,98 @@ func TestFolderServiceImpl_Create(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "05. it should allow the same folder name at the root of another user.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Mark"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+					"mark": {Name: "Mark"},
+				},
+			},
+			args: args{
+				name:      "Work",
+				createdBy: "Luke",
+			},
+			want: &models.Folder{
+				ID:        1002,
+				Name:      "Work",
+				CreatedBy: "Luke",
+			},
+			wantErr: false,
+		},
+		{
+			name: "06. it should add the folder under the parent given by path.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+			},
+			args: args{
+				name:      "/work/reports/2026",
+				createdBy: "Luke",
+			},
+			want: &models.Folder{
+				ID:        1003,
+				ParentID:  1002,
+				Name:      "2026",
+				CreatedBy: "Luke",
+			},
+			wantErr: false,
+		},
+		{
+			name: "07. it should return error when the parent folder does not exist.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+			},
+			args: args{
+				name:      "/work/reports/2026",
+				createdBy: "Luke",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "08. it should return error when the name already exists under the parent.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+			},
+			args: args{
+				name:      "1001/REPORTS",
+				createdBy: "Luke",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:        tt.fields.users,
+				folders:      tt.fields.folders,
+				nextFolderID: 1001,
+			}
 			service := &FolderServiceImpl{
-				folders: tt.fields.folders,
-				userService: &UserServiceImpl{
-					users: tt.fields.users,
-				},
-				nextKey: 1001,
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
 			}
 			got, err := service.Create(tt.args.name, tt.args.createdBy, tt.args.desc)
 			if (err != nil) != tt.wantErr {
@@ -135,10 +218,12 @@ func TestFolderServiceImpl_Delete(t *testing.T) {
 	type fields struct {
 		folders map[int]*models.Folder
 		users   map[string]models.User
+		files   map[int]models.File
 	}
 	type args struct {
 		id        int
 		deletedBy string
+		recursive bool
 	}
 	tests := []struct {
 		name    string
@@ -196,17 +281,78 @@ func TestFolderServiceImpl_Delete(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "04. it should return error if folder holds files without recursive.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", FolderID: 1001},
+				},
+			},
+			args: args{
+				id:        1001,
+				deletedBy: "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "05. it should return error if folder holds subfolders without recursive.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+			},
+			args: args{
+				id:        1001,
+				deletedBy: "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "06. it should delete non-empty folder with recursive.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{
+					"luke": {Name: "Luke"},
+				},
+				files: map[int]models.File{
+					1: {ID: 1, Name: "1.tc", FolderID: 1002},
+				},
+			},
+			args: args{
+				id:        1001,
+				deletedBy: "Luke",
+				recursive: true,
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:        tt.fields.users,
+				folders:      tt.fields.folders,
+				files:        tt.fields.files,
+				nextFolderID: 1001,
+			}
 			service := &FolderServiceImpl{
-				folders: tt.fields.folders,
-				userService: &UserServiceImpl{
-					users: tt.fields.users,
-				},
-				nextKey: 1001,
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
 			}
-			if err := service.Delete(tt.args.id, tt.args.deletedBy); (err != nil) != tt.wantErr {
+			if err := service.Delete(tt.args.id, tt.args.deletedBy, tt.args.recursive); (err != nil) != tt.wantErr {
 				t.Errorf("FolderServiceImpl.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -234,7 +380,7 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 		// TODO: Username is taken for the purpose of authentication.
 		// TODO: “Warning - empty folders”
 		{
-			name: "01. it should return all folders in the system without errors.",
+			name: "01. it should return only the folders the user can read.",
 			fields: fields{
 				folders: map[int]*models.Folder{
 					1001: {Name: "Work", CreatedBy: "Luke"},
@@ -249,7 +395,6 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 				username: "Luke",
 			},
 			want: []models.Folder{
-				{Name: "Testing", CreatedBy: "Mark"},
 				{Name: "Work", CreatedBy: "Luke"},
 			},
 			wantErr: false,
@@ -259,7 +404,7 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{
 					1001: {Name: "Work", CreatedBy: "Luke"},
-					1002: {Name: "Testing", CreatedBy: "Mark"},
+					1002: {Name: "Testing", CreatedBy: "Luke"},
 				},
 				users: map[string]models.User{
 					"luke": {Name: "Luke"},
@@ -272,7 +417,7 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "asc",
 			},
 			want: []models.Folder{
-				{Name: "Testing", CreatedBy: "Mark"},
+				{Name: "Testing", CreatedBy: "Luke"},
 				{Name: "Work", CreatedBy: "Luke"},
 			},
 			wantErr: false,
@@ -282,7 +427,7 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{
 					1001: {Name: "Work", CreatedBy: "Luke"},
-					1002: {Name: "Testing", CreatedBy: "Mark"},
+					1002: {Name: "Testing", CreatedBy: "Luke"},
 				},
 				users: map[string]models.User{
 					"luke": {Name: "Luke"},
@@ -296,7 +441,7 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			},
 			want: []models.Folder{
 				{Name: "Work", CreatedBy: "Luke"},
-				{Name: "Testing", CreatedBy: "Mark"},
+				{Name: "Testing", CreatedBy: "Luke"},
 			},
 			wantErr: false,
 		},
@@ -305,8 +450,8 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{
 					1001: {Name: "Work", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					1002: {Name: "Testing", CreatedBy: "Mark", CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
-					1003: {Name: "Boss", CreatedBy: "April", CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					1002: {Name: "Testing", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+					1003: {Name: "Boss", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
 				},
 				users: map[string]models.User{
 					"luke":  {Name: "Luke"},
@@ -321,8 +466,8 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			},
 			want: []models.Folder{
 				{Name: "Work", CreatedBy: "Luke"},
-				{Name: "Boss", CreatedBy: "April"},
-				{Name: "Testing", CreatedBy: "Mark"},
+				{Name: "Boss", CreatedBy: "Luke"},
+				{Name: "Testing", CreatedBy: "Luke"},
 			},
 			wantErr: false,
 		},
@@ -331,8 +476,8 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 			fields: fields{
 				folders: map[int]*models.Folder{
 					1001: {Name: "Work", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 24, 0, 0, 0, 0, time.UTC)},
-					1002: {Name: "Testing", CreatedBy: "Mark", CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
-					1003: {Name: "Boss", CreatedBy: "April", CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
+					1002: {Name: "Testing", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 26, 0, 0, 0, 0, time.UTC)},
+					1003: {Name: "Boss", CreatedBy: "Luke", CreatedAt: time.Date(2021, 2, 25, 0, 0, 0, 0, time.UTC)},
 				},
 				users: map[string]models.User{
 					"luke":  {Name: "Luke"},
@@ -346,8 +491,8 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 				sortOrder: "dsc",
 			},
 			want: []models.Folder{
-				{Name: "Testing", CreatedBy: "Mark"},
-				{Name: "Boss", CreatedBy: "April"},
+				{Name: "Testing", CreatedBy: "Luke"},
+				{Name: "Boss", CreatedBy: "Luke"},
 				{Name: "Work", CreatedBy: "Luke"},
 			},
 			wantErr: false,
@@ -355,12 +500,15 @@ func TestFolderServiceImpl_GetAll(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:        tt.fields.users,
+				folders:      tt.fields.folders,
+				nextFolderID: tt.fields.initKey,
+			}
 			service := &FolderServiceImpl{
-				folders: tt.fields.folders,
-				userService: &UserServiceImpl{
-					users: tt.fields.users,
-				},
-				nextKey: tt.fields.initKey,
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
 			}
 			got, err := service.GetAll(tt.args.username, tt.args.sortBy, tt.args.sortOrder)
 			if (err != nil) != tt.wantErr {
@@ -407,7 +555,7 @@ func TestFolderServiceImpl_Rename(t *testing.T) {
 		{
 			name: "01. it should rename folder without error.",
 			fields: fields{
-				folders: map[int]*models.Folder{1001: {Name: "Work"}},
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
 			},
 			args: args{
@@ -420,7 +568,7 @@ func TestFolderServiceImpl_Rename(t *testing.T) {
 		{
 			name: "02. it should return error if folder not found.",
 			fields: fields{
-				folders: map[int]*models.Folder{1001: {Name: "Work"}},
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
 			},
 			args: args{
@@ -433,7 +581,7 @@ func TestFolderServiceImpl_Rename(t *testing.T) {
 		{
 			name: "03. it should return error if user not found.",
 			fields: fields{
-				folders: map[int]*models.Folder{1001: {Name: "Work"}},
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
 				users:   map[string]models.User{"luke": {Name: "Luke"}},
 			},
 			args: args{
@@ -443,15 +591,47 @@ func TestFolderServiceImpl_Rename(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "04. it should return error if user does not own the folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+			},
+			args: args{
+				id:        1001,
+				name:      "Work2",
+				renamedBy: "Mark",
+			},
+			wantErr: true,
+		},
+		{
+			name: "05. it should return error if name already exists under the same parent.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, Name: "Work2", CreatedBy: "Luke"},
+				},
+				users: map[string]models.User{"luke": {Name: "Luke"}},
+			},
+			args: args{
+				id:        1001,
+				name:      "work2",
+				renamedBy: "Luke",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:        tt.fields.users,
+				folders:      tt.fields.folders,
+				nextFolderID: 1001,
+			}
 			service := &FolderServiceImpl{
-				folders: tt.fields.folders,
-				userService: &UserServiceImpl{
-					users: tt.fields.users,
-				},
-				nextKey: 1001,
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
 			}
 			if err := service.Rename(tt.args.id, tt.args.name, tt.args.renamedBy); (err != nil) != tt.wantErr {
 				t.Errorf("FolderServiceImpl.Rename() error = %v, wantErr %v", err, tt.wantErr)
@@ -459,3 +639,171 @@ func TestFolderServiceImpl_Rename(t *testing.T) {
 		})
 	}
 }
+
+func TestFolderServiceImpl_Resolve(t *testing.T) {
+	folders := map[int]*models.Folder{
+		1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+		1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+		1003: {ID: 1003, ParentID: 1002, Name: "2026", CreatedBy: "Luke"},
+		1004: {ID: 1004, Name: "Work", CreatedBy: "Mark"},
+	}
+	users := map[string]models.User{
+		"luke": {Name: "Luke"},
+		"mark": {Name: "Mark"},
+	}
+	tests := []struct {
+		name     string
+		username string
+		ref      string
+		want     int
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "01. it should resolve folder by ID.", username: "Luke", ref: "1002", want: 1002, wantPath: "/Work/Reports"},
+		{name: "02. it should resolve absolute path from the root of the user.", username: "Luke", ref: "/work/reports/2026", want: 1003, wantPath: "/Work/Reports/2026"},
+		{name: "03. it should resolve the same path to the folder of another user.", username: "Mark", ref: "/Work", want: 1004, wantPath: "/Work"},
+		{name: "04. it should resolve relative path from a folder ID.", username: "Luke", ref: "1001/Reports/2026", want: 1003, wantPath: "/Work/Reports/2026"},
+		{name: "05. it should resolve parent segments.", username: "Luke", ref: "/work/reports/2026/../..", want: 1001, wantPath: "/Work"},
+		{name: "06. it should return error when path does not exist.", username: "Luke", ref: "/work/temp", wantErr: true},
+		{name: "07. it should return error when ID does not exist.", username: "Luke", ref: "9999", wantErr: true},
+		{name: "08. it should return error for the root itself.", username: "Luke", ref: "/", wantErr: true},
+		{name: "09. it should return error when user does not exist.", username: "abc", ref: "/work", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{users: users, folders: folders}
+			service := &FolderServiceImpl{
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
+			}
+			got, err := service.Resolve(tt.username, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FolderServiceImpl.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != tt.want {
+				t.Errorf("FolderServiceImpl.Resolve() = %v, want %v", got.ID, tt.want)
+			}
+			if path, _ := service.Path(got.ID); path != tt.wantPath {
+				t.Errorf("FolderServiceImpl.Path() = %v, want %v", path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestFolderServiceImpl_Delete_MovesToTrash(t *testing.T) {
+	store := &MemoryStore{
+		users: map[string]models.User{"luke": {Name: "Luke"}},
+		folders: map[int]*models.Folder{
+			1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+			1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+			1003: {ID: 1003, Name: "Home", CreatedBy: "Luke"},
+		},
+		files: map[int]models.File{
+			1: {ID: 1, Name: "1.tc", FolderID: 1002},
+			2: {ID: 2, Name: "2.tc", FolderID: 1003},
+		},
+	}
+	service := &FolderServiceImpl{
+		store:       store,
+		userService: &UserServiceImpl{store: store},
+		authorizer:  &AuthorizerImpl{store: store},
+	}
+
+	if err := service.Delete(1001, "Luke", true); err != nil {
+		t.Fatalf("FolderServiceImpl.Delete() error = %v", err)
+	}
+
+	if len(store.folders) != 1 || len(store.files) != 1 {
+		t.Errorf("FolderServiceImpl.Delete() left %d folders and %d files, want 1 and 1", len(store.folders), len(store.files))
+	}
+
+	item, exists := store.trash[1]
+	if !exists || item.Path != "/Work" || len(item.Folders) != 2 || len(item.Files) != 1 {
+		t.Errorf("FolderServiceImpl.Delete() trash item = %+v", item)
+	}
+}
+
+func TestFolderServiceImpl_Move(t *testing.T) {
+	type args struct {
+		id       int
+		parentID int
+		name     string
+		movedBy  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "01. it should move the folder under another folder.",
+			args: args{
+				id:       1003,
+				parentID: 1002,
+				name:     "Home",
+				movedBy:  "Luke",
+			},
+			wantErr: false,
+		},
+		{
+			name: "02. it should return error if the folder is moved into its subfolder.",
+			args: args{
+				id:       1001,
+				parentID: 1002,
+				name:     "Work",
+				movedBy:  "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "03. it should return error if the name exists under the new parent.",
+			args: args{
+				id:       1003,
+				parentID: 1001,
+				name:     "reports",
+				movedBy:  "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "04. it should return error if user is not allowed to write the folder.",
+			args: args{
+				id:       1003,
+				parentID: 1002,
+				name:     "Home",
+				movedBy:  "Mark",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users: map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				folders: map[int]*models.Folder{
+					1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"},
+					1002: {ID: 1002, ParentID: 1001, Name: "Reports", CreatedBy: "Luke"},
+					1003: {ID: 1003, Name: "Home", CreatedBy: "Luke"},
+				},
+			}
+			service := &FolderServiceImpl{
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
+			}
+			if err := service.Move(tt.args.id, tt.args.parentID, tt.args.name, tt.args.movedBy); (err != nil) != tt.wantErr {
+				t.Errorf("FolderServiceImpl.Move() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && store.folders[tt.args.id].ParentID != tt.args.parentID {
+				t.Errorf("FolderServiceImpl.Move() parent = %v, want %v", store.folders[tt.args.id].ParentID, tt.args.parentID)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"virtual-file-system/internal/models"
+)
+
+// GroupPrefix marks a grantee as a group rather than a user, e.g. "@squad".
+const GroupPrefix = "@"
+
+// GroupService is responsible for CRUD operations against a user group
+type GroupService interface {
+	// Create adds a group to the system with `createdBy` as its first member.
+	// If the given `createdBy` does not match existing users in the system, an error is returned.
+	// If the group already exists, an error is returned.
+	Create(name string, createdBy string) (*models.Group, error)
+
+	// AddMember puts the member into the group.
+	// If the given `addedBy` did not create the group, an error is returned.
+	// If the member does not exist or is already in the group, an error is returned.
+	AddMember(name string, member string, addedBy string) error
+
+	// RemoveMember takes the member out of the group.
+	// If the given `removedBy` did not create the group, an error is returned.
+	// If the member is not in the group, an error is returned.
+	RemoveMember(name string, member string, removedBy string) error
+
+	// Exists returns true if the given group name exists in the internal group storage.
+	// The group name comparison is case insensitive
+	Exists(name string) bool
+
+	// GetByMember returns the groups the user is a member of.
+	GetByMember(username string) []models.Group
+}
+
+// GroupServiceImpl is the implementation of the GroupService interface
+type GroupServiceImpl struct {
+	store       GroupStore
+	userService UserService
+	locks       *lockTable
+}
+
+// Create adds a group to the system with `createdBy` as its first member.
+// If the given `createdBy` does not match existing users in the system, an error is returned.
+// If the group already exists, an error is returned.
+func (service *GroupServiceImpl) Create(name string, createdBy string) (*models.Group, error) {
+	if !service.userService.Exists(createdBy) {
+		return nil, errUnknownUser(createdBy)
+	}
+
+	name = strings.TrimPrefix(name, GroupPrefix)
+	if strings.TrimSpace(name) == "" {
+		return nil, newError(ErrInvalid, "group", name, "group name should not be empty")
+	}
+
+	defer service.locks.lock(groupLockKey(name))()
+
+	if service.Exists(name) {
+		return nil, newError(ErrAlreadyExists, "group", name, "group already exists")
+	}
+
+	group := &models.Group{
+		Name:      name,
+		Members:   []string{createdBy},
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+
+	if err := service.store.PutGroup(makeGroupKey(name), *group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// AddMember puts the member into the group.
+// If the given `addedBy` did not create the group, an error is returned.
+// If the member does not exist or is already in the group, an error is returned.
+func (service *GroupServiceImpl) AddMember(name string, member string, addedBy string) error {
+	defer service.locks.lock(groupLockKey(name))()
+
+	group, err := service.getOwnedGroup(name, addedBy)
+	if err != nil {
+		return err
+	}
+
+	if !service.userService.Exists(member) {
+		return errNotFound("member", member)
+	}
+
+	if isGroupMember(*group, member) {
+		return newError(ErrAlreadyExists, "member", member, "user is already a member of the group")
+	}
+
+	group.Members = append(group.Members, member)
+	return service.store.PutGroup(makeGroupKey(group.Name), *group)
+}
+
+// RemoveMember takes the member out of the group.
+// If the given `removedBy` did not create the group, an error is returned.
+// If the member is not in the group, an error is returned.
+func (service *GroupServiceImpl) RemoveMember(name string, member string, removedBy string) error {
+	defer service.locks.lock(groupLockKey(name))()
+
+	group, err := service.getOwnedGroup(name, removedBy)
+	if err != nil {
+		return err
+	}
+
+	members := make([]string, 0, len(group.Members))
+	for _, m := range group.Members {
+		if !strings.EqualFold(m, member) {
+			members = append(members, m)
+		}
+	}
+
+	if len(members) == len(group.Members) {
+		return newError(ErrNotFound, "member", member, "user is not a member of the group")
+	}
+
+	group.Members = members
+	return service.store.PutGroup(makeGroupKey(group.Name), *group)
+}
+
+// Exists returns true if the given group name exists in the internal group storage.
+// The group name comparison is case insensitive
+func (service *GroupServiceImpl) Exists(name string) bool {
+	_, exists := service.store.GetGroup(makeGroupKey(name))
+
+	return exists
+}
+
+// GetByMember returns the groups the user is a member of.
+func (service *GroupServiceImpl) GetByMember(username string) []models.Group {
+	return groupsOf(service.store, username)
+}
+
+func (service *GroupServiceImpl) getOwnedGroup(name string, username string) (*models.Group, error) {
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	group, exists := service.store.GetGroup(makeGroupKey(name))
+	if !exists {
+		return nil, errNotFound("group", name)
+	}
+
+	if !strings.EqualFold(group.CreatedBy, username) {
+		return nil, newError(ErrPermissionDenied, "group", name, "permission denied: only the creator can change the members of the group")
+	}
+
+	return &group, nil
+}
+
+// groupsOf returns the groups the user is a member of.
+func groupsOf(store GroupStore, username string) []models.Group {
+	var groups []models.Group
+	for _, group := range store.ListGroups() {
+		if isGroupMember(group, username) {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+func isGroupMember(group models.Group, username string) bool {
+	for _, m := range group.Members {
+		if strings.EqualFold(m, username) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// makeGroupKey returns the key of the group, whose name is case insensitive and may carry the GroupPrefix.
+func makeGroupKey(name string) string {
+	return strings.ToLower(strings.TrimPrefix(name, GroupPrefix))
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+func TestGroupServiceImpl_Create(t *testing.T) {
+	tests := []struct {
+		name      string
+		groups    map[string]models.Group
+		group     string
+		createdBy string
+		wantErr   bool
+	}{
+		{
+			name:      "01. it should add new group with the creator as member.",
+			groups:    map[string]models.Group{},
+			group:     "Squad",
+			createdBy: "Luke",
+			wantErr:   false,
+		},
+		{
+			name:      "02. it should return error if group already exists using case-insensitive comparison.",
+			groups:    map[string]models.Group{"squad": {Name: "Squad", CreatedBy: "Mark"}},
+			group:     "@SQUAD",
+			createdBy: "Luke",
+			wantErr:   true,
+		},
+		{
+			name:      "03. it should return error if user does not exist.",
+			groups:    map[string]models.Group{},
+			group:     "Squad",
+			createdBy: "abc",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:  map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				groups: tt.groups,
+			}
+			service := &GroupServiceImpl{store: store, userService: &UserServiceImpl{store: store}}
+			got, err := service.Create(tt.group, tt.createdBy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GroupServiceImpl.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !isGroupMember(*got, tt.createdBy) {
+				t.Errorf("GroupServiceImpl.Create() creator is not a member of %v", got)
+			}
+		})
+	}
+}
+
+func TestGroupServiceImpl_Membership(t *testing.T) {
+	store := &MemoryStore{
+		users: map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}, "april": {Name: "April"}},
+		folders: map[int]*models.Folder{
+			1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"},
+		},
+	}
+	authorizer := &AuthorizerImpl{store: store}
+	groupService := &GroupServiceImpl{store: store, userService: &UserServiceImpl{store: store}}
+	shareService := &ShareServiceImpl{
+		store:         store,
+		userService:   &UserServiceImpl{store: store},
+		folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+		groupService:  groupService,
+		authorizer:    authorizer,
+	}
+	folder := *store.folders[1001]
+
+	if _, err := groupService.Create("squad", "Luke"); err != nil {
+		t.Fatalf("GroupServiceImpl.Create() error = %v", err)
+	}
+	if err := shareService.Share(1001, "@squad", models.AccessReadWrite, "Luke"); err != nil {
+		t.Fatalf("ShareServiceImpl.Share() error = %v", err)
+	}
+
+	if got := authorizer.FolderPermission("Mark", folder); got != PermissionNone {
+		t.Errorf("FolderPermission() before joining = %v, want %v", got, PermissionNone)
+	}
+
+	if err := groupService.AddMember("squad", "Mark", "Mark"); err == nil {
+		t.Errorf("GroupServiceImpl.AddMember() by a non-creator should return error")
+	}
+	if err := groupService.AddMember("squad", "Mark", "Luke"); err != nil {
+		t.Fatalf("GroupServiceImpl.AddMember() error = %v", err)
+	}
+	if err := groupService.AddMember("squad", "mark", "Luke"); err == nil {
+		t.Errorf("GroupServiceImpl.AddMember() of an existing member should return error")
+	}
+
+	if got := authorizer.FolderPermission("Mark", folder); got != PermissionWrite {
+		t.Errorf("FolderPermission() after joining = %v, want %v", got, PermissionWrite)
+	}
+	if got := authorizer.FolderPermission("April", folder); got != PermissionNone {
+		t.Errorf("FolderPermission() of a non-member = %v, want %v", got, PermissionNone)
+	}
+
+	if err := groupService.RemoveMember("squad", "MARK", "Luke"); err != nil {
+		t.Fatalf("GroupServiceImpl.RemoveMember() error = %v", err)
+	}
+	if got := authorizer.FolderPermission("Mark", folder); got != PermissionNone {
+		t.Errorf("FolderPermission() after leaving = %v, want %v", got, PermissionNone)
+	}
+	if err := groupService.RemoveMember("squad", "Mark", "Luke"); err == nil {
+		t.Errorf("GroupServiceImpl.RemoveMember() of a non-member should return error")
+	}
+}
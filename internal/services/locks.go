@@ -0,0 +1,165 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lockTable serializes the services changing the same part of the store, so concurrent callers
+// only wait for each other when they touch the same folder, content, deleted item, user or group.
+//
+// Operations changing the shape of the folder hierarchy, such as moving or deleting a folder together
+// with everything below it, hold the tree lock exclusively. Every other change holds it shared, then the
+// locks of the keys it changes. Locks are always taken in that order, keys sorted, and the lock of a
+// content last and alone, so they cannot deadlock.
+//
+// A nil lockTable does no locking, for services used by a single goroutine.
+type lockTable struct {
+	tree sync.RWMutex
+
+	mu   sync.Mutex
+	keys map[string]*keyLock
+}
+
+// keyLock is the lock of a key, dropped from the table once nobody holds or waits for it.
+type keyLock struct {
+	sync.RWMutex
+	refs int
+}
+
+func newLockTable() *lockTable {
+	return &lockTable{keys: make(map[string]*keyLock)}
+}
+
+// lockTree holds the tree lock exclusively and returns the function releasing it.
+func (t *lockTable) lockTree() func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.tree.Lock()
+	return t.tree.Unlock
+}
+
+// rlockTree holds the tree lock shared and returns the function releasing it.
+func (t *lockTable) rlockTree() func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.tree.RLock()
+	return t.tree.RUnlock
+}
+
+// lock holds the locks of the keys exclusively and returns the function releasing them.
+func (t *lockTable) lock(keys ...string) func() {
+	return t.acquire(false, keys)
+}
+
+// rlock holds the locks of the keys shared and returns the function releasing them.
+func (t *lockTable) rlock(keys ...string) func() {
+	return t.acquire(true, keys)
+}
+
+func (t *lockTable) acquire(shared bool, keys []string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+
+	held := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if i > 0 && keys[i-1] == key {
+			continue
+		}
+
+		l := t.ref(key)
+		if shared {
+			l.RLock()
+		} else {
+			l.Lock()
+		}
+		held = append(held, key)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			t.unref(held[i], shared)
+		}
+	}
+}
+
+// ref returns the lock of the key, creating it if needed.
+func (t *lockTable) ref(key string) *keyLock {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.keys == nil {
+		t.keys = make(map[string]*keyLock)
+	}
+
+	l, exists := t.keys[key]
+	if !exists {
+		l = &keyLock{}
+		t.keys[key] = l
+	}
+	l.refs++
+
+	return l
+}
+
+// unref releases the lock of the key, dropping it once nobody holds or waits for it anymore.
+func (t *lockTable) unref(key string, shared bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l := t.keys[key]
+	if shared {
+		l.RUnlock()
+	} else {
+		l.Unlock()
+	}
+
+	l.refs--
+	if l.refs == 0 {
+		delete(t.keys, key)
+	}
+}
+
+// folderLockKey guards the names of the folders and files under the folder, its files and its grants.
+func folderLockKey(id int) string {
+	return "folder:" + strconv.Itoa(id)
+}
+
+// childrenLockKey guards the names of the folders under the parent, or at the root of the owner if parentID is 0.
+func childrenLockKey(parentID int, owner string) string {
+	if parentID == 0 {
+		return "root:" + strings.ToLower(owner)
+	}
+
+	return folderLockKey(parentID)
+}
+
+// blobLockKey guards the content with given checksum from being released while a file is about to refer to it.
+func blobLockKey(checksum string) string {
+	return "blob:" + checksum
+}
+
+// trashLockKey guards the deleted item with given ID.
+func trashLockKey(id int) string {
+	return "trash:" + strconv.Itoa(id)
+}
+
+// userLockKey guards the user name.
+func userLockKey(name string) string {
+	return "user:" + strings.ToLower(name)
+}
+
+// groupLockKey guards the group and its members.
+func groupLockKey(name string) string {
+	return "group:" + makeGroupKey(name)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockTable_Lock(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    []string
+		keys    []string
+		blocked bool
+	}{
+		{
+			name:    "01. it should wait for the same key.",
+			held:    []string{folderLockKey(1001)},
+			keys:    []string{folderLockKey(1001)},
+			blocked: true,
+		},
+		{
+			name: "02. it should not wait for other keys.",
+			held: []string{folderLockKey(1001)},
+			keys: []string{folderLockKey(1002)},
+		},
+		{
+			name:    "03. it should wait when any of the keys is held.",
+			held:    []string{folderLockKey(1002)},
+			keys:    []string{folderLockKey(1001), folderLockKey(1002)},
+			blocked: true,
+		},
+		{
+			name: "04. it should accept the same key twice.",
+			keys: []string{folderLockKey(1001), folderLockKey(1001)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := newLockTable()
+			unlock := locks.lock(tt.held...)
+
+			acquired := make(chan struct{})
+			go func() {
+				locks.lock(tt.keys...)()
+				close(acquired)
+			}()
+
+			select {
+			case <-acquired:
+				if tt.blocked {
+					t.Errorf("lockTable.lock() did not wait for %v", tt.held)
+				}
+			case <-time.After(50 * time.Millisecond):
+				if !tt.blocked {
+					t.Errorf("lockTable.lock() waited for %v", tt.held)
+				}
+			}
+
+			unlock()
+			<-acquired
+
+			if len(locks.keys) != 0 {
+				t.Errorf("lockTable.keys = %v, want empty once released", locks.keys)
+			}
+		})
+	}
+}
+
+func TestLockTable_Concurrent(t *testing.T) {
+	locks := newLockTable()
+	counters := make(map[string]int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Any two pairs of keys overlap, so the counters are never written concurrently.
+			keys := []string{folderLockKey(i % 3), folderLockKey((i + 1) % 3)}
+			for j := 0; j < 100; j++ {
+				unlockTree := locks.rlockTree()
+				unlock := locks.lock(keys...)
+				for _, key := range keys {
+					counters[key]++
+				}
+				unlock()
+				unlockTree()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range counters {
+		total += n
+	}
+	if total != 50*100*2 {
+		t.Errorf("counters total = %d, want %d", total, 50*100*2)
+	}
+}
+
+func TestLockTable_Nil(t *testing.T) {
+	var locks *lockTable
+	locks.lockTree()()
+	locks.rlockTree()()
+	locks.lock(folderLockKey(1001))()
+	locks.rlock(folderLockKey(1001))()
+}
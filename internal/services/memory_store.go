@@ -0,0 +1,337 @@
+package services
+
+import (
+	"sync"
+	"virtual-file-system/internal/models"
+)
+
+const (
+	// firstFolderID is the ID given to the very first folder.
+	firstFolderID = 1001
+
+	// firstFileID is the ID given to the very first file.
+	firstFileID = 1
+
+	// firstTrashID is the ID given to the very first deleted item.
+	firstTrashID = 1
+)
+
+// MemoryStore is the Store implementation backed by Go maps.
+// Nothing is kept once the process exits. It is safe for concurrent use,
+// every write going through apply under the write lock.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	users        map[string]models.User
+	folders      map[int]*models.Folder
+	files        map[int]models.File
+	blobs        map[string][]byte
+	grants       map[string]models.Grant
+	groups       map[string]models.Group
+	trash        map[int]models.TrashItem
+	nextFolderID int
+	nextFileID   int
+	nextTrashID  int
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:        make(map[string]models.User),
+		folders:      make(map[int]*models.Folder),
+		files:        make(map[int]models.File),
+		blobs:        make(map[string][]byte),
+		grants:       make(map[string]models.Grant),
+		groups:       make(map[string]models.Group),
+		trash:        make(map[int]models.TrashItem),
+		nextFolderID: firstFolderID,
+		nextFileID:   firstFileID,
+		nextTrashID:  firstTrashID,
+	}
+}
+
+// GetUser returns the user stored under the given key.
+func (store *MemoryStore) GetUser(key string) (models.User, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	user, exists := store.users[key]
+	return user, exists
+}
+
+// PutUser stores the user under the given key, replacing any existing one.
+func (store *MemoryStore) PutUser(key string, user models.User) error {
+	return store.apply(walRecord{Op: opPutUser, Key: key, User: &user})
+}
+
+// DeleteUser removes the user stored under the given key.
+func (store *MemoryStore) DeleteUser(key string) error {
+	return store.apply(walRecord{Op: opDeleteUser, Key: key})
+}
+
+// ListUsers returns all stored users in no particular order.
+func (store *MemoryStore) ListUsers() []models.User {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	users := make([]models.User, 0, len(store.users))
+	for _, user := range store.users {
+		users = append(users, user)
+	}
+
+	return users
+}
+
+// GetFolder returns the folder with given ID.
+func (store *MemoryStore) GetFolder(id int) (models.Folder, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	folder, exists := store.folders[id]
+	if !exists {
+		return models.Folder{}, false
+	}
+
+	return *folder, true
+}
+
+// PutFolder stores the folder under the given ID, replacing any existing one.
+func (store *MemoryStore) PutFolder(id int, folder models.Folder) error {
+	return store.apply(walRecord{Op: opPutFolder, ID: id, Folder: &folder})
+}
+
+// DeleteFolder removes the folder with given ID.
+func (store *MemoryStore) DeleteFolder(id int) error {
+	return store.apply(walRecord{Op: opDeleteFolder, ID: id})
+}
+
+// ListFolders returns all stored folders in no particular order.
+func (store *MemoryStore) ListFolders() []models.Folder {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	folders := make([]models.Folder, 0, len(store.folders))
+	for _, folder := range store.folders {
+		folders = append(folders, *folder)
+	}
+
+	return folders
+}
+
+// NextFolderID reserves an unused folder ID.
+// IDs are never reused, even after the folder holding it is deleted.
+func (store *MemoryStore) NextFolderID() (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.nextFolderID < firstFolderID {
+		store.nextFolderID = firstFolderID
+	}
+
+	for {
+		id := store.nextFolderID
+		store.nextFolderID++
+
+		if _, exists := store.folders[id]; !exists {
+			return id, nil
+		}
+	}
+}
+
+// GetFile returns the file with given ID.
+func (store *MemoryStore) GetFile(id int) (models.File, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	file, exists := store.files[id]
+	return file, exists
+}
+
+// PutFile stores the file under the given ID, replacing any existing one.
+func (store *MemoryStore) PutFile(id int, file models.File) error {
+	return store.apply(walRecord{Op: opPutFile, ID: id, File: &file})
+}
+
+// DeleteFile removes the file with given ID.
+func (store *MemoryStore) DeleteFile(id int) error {
+	return store.apply(walRecord{Op: opDeleteFile, ID: id})
+}
+
+// ListFiles returns all stored files in no particular order.
+func (store *MemoryStore) ListFiles() []models.File {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	files := make([]models.File, 0, len(store.files))
+	for _, file := range store.files {
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// NextFileID reserves an unused file ID.
+// IDs are never reused, even after the file holding it is deleted.
+func (store *MemoryStore) NextFileID() (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.nextFileID < firstFileID {
+		store.nextFileID = firstFileID
+	}
+
+	for {
+		id := store.nextFileID
+		store.nextFileID++
+
+		if _, exists := store.files[id]; !exists {
+			return id, nil
+		}
+	}
+}
+
+// GetBlob returns the content with given checksum.
+func (store *MemoryStore) GetBlob(checksum string) ([]byte, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	data, exists := store.blobs[checksum]
+	return data, exists
+}
+
+// PutBlob stores the content under the given checksum.
+func (store *MemoryStore) PutBlob(checksum string, data []byte) error {
+	return store.apply(walRecord{Op: opPutBlob, Key: checksum, Data: data})
+}
+
+// DeleteBlob removes the content with given checksum.
+func (store *MemoryStore) DeleteBlob(checksum string) error {
+	return store.apply(walRecord{Op: opDeleteBlob, Key: checksum})
+}
+
+// GetGrant returns the grant stored under the given key.
+func (store *MemoryStore) GetGrant(key string) (models.Grant, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	grant, exists := store.grants[key]
+	return grant, exists
+}
+
+// PutGrant stores the grant under the given key, replacing any existing one.
+func (store *MemoryStore) PutGrant(key string, grant models.Grant) error {
+	return store.apply(walRecord{Op: opPutGrant, Key: key, Grant: &grant})
+}
+
+// DeleteGrant removes the grant stored under the given key.
+func (store *MemoryStore) DeleteGrant(key string) error {
+	return store.apply(walRecord{Op: opDeleteGrant, Key: key})
+}
+
+// ListGrants returns all stored grants in no particular order.
+func (store *MemoryStore) ListGrants() []models.Grant {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	grants := make([]models.Grant, 0, len(store.grants))
+	for _, grant := range store.grants {
+		grants = append(grants, grant)
+	}
+
+	return grants
+}
+
+// GetGroup returns the group stored under the given key.
+func (store *MemoryStore) GetGroup(key string) (models.Group, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	group, exists := store.groups[key]
+	return group, exists
+}
+
+// PutGroup stores the group under the given key, replacing any existing one.
+func (store *MemoryStore) PutGroup(key string, group models.Group) error {
+	return store.apply(walRecord{Op: opPutGroup, Key: key, Group: &group})
+}
+
+// DeleteGroup removes the group stored under the given key.
+func (store *MemoryStore) DeleteGroup(key string) error {
+	return store.apply(walRecord{Op: opDeleteGroup, Key: key})
+}
+
+// ListGroups returns all stored groups in no particular order.
+func (store *MemoryStore) ListGroups() []models.Group {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	groups := make([]models.Group, 0, len(store.groups))
+	for _, group := range store.groups {
+		groups = append(groups, group)
+	}
+
+	return groups
+}
+
+// GetTrash returns the item with given ID.
+func (store *MemoryStore) GetTrash(id int) (models.TrashItem, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	item, exists := store.trash[id]
+	return item, exists
+}
+
+// PutTrash stores the item under the given ID, replacing any existing one.
+func (store *MemoryStore) PutTrash(id int, item models.TrashItem) error {
+	return store.apply(walRecord{Op: opPutTrash, ID: id, Trash: &item})
+}
+
+// DeleteTrash removes the item with given ID.
+func (store *MemoryStore) DeleteTrash(id int) error {
+	return store.apply(walRecord{Op: opDeleteTrash, ID: id})
+}
+
+// ListTrash returns all stored items in no particular order.
+func (store *MemoryStore) ListTrash() []models.TrashItem {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	items := make([]models.TrashItem, 0, len(store.trash))
+	for _, item := range store.trash {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// NextTrashID reserves an unused item ID.
+// IDs are never reused, even after the item holding it is restored or purged.
+func (store *MemoryStore) NextTrashID() (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.nextTrashID < firstTrashID {
+		store.nextTrashID = firstTrashID
+	}
+
+	for {
+		id := store.nextTrashID
+		store.nextTrashID++
+
+		if _, exists := store.trash[id]; !exists {
+			return id, nil
+		}
+	}
+}
+
+// Batch runs fn against a view of the store whose writes are applied all together once fn returns.
+// Nothing is written if fn returns an error.
+func (store *MemoryStore) Batch(fn func(Store) error) error {
+	batch := &batchStore{Store: store}
+	if err := fn(batch); err != nil {
+		return err
+	}
+
+	return store.apply(walRecord{Op: opBatch, Records: batch.records})
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+func TestMemoryStore_NextFolderID(t *testing.T) {
+	type fields struct {
+		folders      map[int]*models.Folder
+		nextFolderID int
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   []int
+	}{
+		{
+			name:   "01. it should start from 1001 when the store is empty.",
+			fields: fields{folders: map[int]*models.Folder{}},
+			want:   []int{1001, 1002, 1003},
+		},
+		{
+			name: "02. it should skip IDs that are already taken.",
+			fields: fields{
+				folders: map[int]*models.Folder{
+					1001: {Name: "Work"},
+					1002: {Name: "Testing"},
+				},
+				nextFolderID: 1001,
+			},
+			want: []int{1003, 1004},
+		},
+		{
+			name: "03. it should not reuse IDs of deleted folders.",
+			fields: fields{
+				folders:      map[int]*models.Folder{},
+				nextFolderID: 1002,
+			},
+			want: []int{1002},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				folders:      tt.fields.folders,
+				nextFolderID: tt.fields.nextFolderID,
+			}
+
+			got := make([]int, 0, len(tt.want))
+			for range tt.want {
+				id, err := store.NextFolderID()
+				if err != nil {
+					t.Errorf("MemoryStore.NextFolderID() error = %v", err)
+					return
+				}
+				got = append(got, id)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MemoryStore.NextFolderID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+const (
+	passwordSaltSize   = 16
+	passwordKeySize    = 32
+	passwordIterations = 10000
+)
+
+// newPasswordSalt returns a random salt in hex.
+func newPasswordSalt() (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(salt), nil
+}
+
+// hashPassword derives the hash of the password with PBKDF2-HMAC-SHA256, returned in hex.
+func hashPassword(password string, salt string) string {
+	prf := hmac.New(sha256.New, []byte(password))
+	key := make([]byte, 0, passwordKeySize)
+
+	for block := uint32(1); len(key) < passwordKeySize; block++ {
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+
+		prf.Reset()
+		prf.Write([]byte(salt))
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < passwordIterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return hex.EncodeToString(key[:passwordKeySize])
+}
+
+// verifyPassword reports whether the password matches the hash, in constant time.
+func verifyPassword(password string, salt string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashPassword(password, salt)), []byte(hash)) == 1
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"virtual-file-system/internal/models"
+)
+
+// ShareService is responsible for sharing folders with other users.
+type ShareService interface {
+	// Share grants the grantee read-only or read-write access to the folder with given id and everything below it.
+	// A grantee starting with GroupPrefix, e.g. "@squad", refers to a group and grants the access to all of its members.
+	// Sharing a folder with the same grantee again replaces the previous access.
+	// If the given `sharedBy` does not match existing users or does not own the folder, an error is returned.
+	// If the given grantee does not match existing users or groups, or already owns the folder, an error is returned.
+	Share(id int, grantee string, access string, sharedBy string) error
+
+	// Unshare revokes the access of the grantee to the folder with given id.
+	// If the given `unsharedBy` does not match existing users or does not own the folder, an error is returned.
+	// If the folder is not shared with the grantee, an error is returned.
+	Unshare(id int, grantee string, unsharedBy string) error
+
+	// GetAll retrieves the grants of the folder with given id, ordered by grantee.
+	// If the given `username` does not match existing users or does not own the folder, an error is returned.
+	GetAll(id int, username string) ([]models.Grant, error)
+}
+
+// ShareServiceImpl is the implementation of the ShareService interface
+type ShareServiceImpl struct {
+	store         GrantStore
+	userService   UserService
+	folderService FolderService
+	groupService  GroupService
+	authorizer    Authorizer
+	locks         *lockTable
+}
+
+// Share grants the grantee read-only or read-write access to the folder with given id and everything below it.
+// A grantee starting with GroupPrefix, e.g. "@squad", refers to a group and grants the access to all of its members.
+// Sharing a folder with the same grantee again replaces the previous access.
+// If the given `sharedBy` does not match existing users or does not own the folder, an error is returned.
+// If the given grantee does not match existing users or groups, or already owns the folder, an error is returned.
+func (service *ShareServiceImpl) Share(id int, grantee string, access string, sharedBy string) error {
+	if access != models.AccessReadOnly && access != models.AccessReadWrite {
+		return newError(ErrInvalid, "access", access, fmt.Sprintf("access should be either %q or %q", models.AccessReadOnly, models.AccessReadWrite))
+	}
+
+	// The folder cannot be deleted meanwhile, which would leave the grant behind.
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(id))()
+
+	f, err := service.getOwnedFolder(id, sharedBy)
+	if err != nil {
+		return err
+	}
+
+	isGroup := strings.HasPrefix(grantee, GroupPrefix)
+	if isGroup {
+		if !service.groupService.Exists(grantee) {
+			return errNotFound("group", grantee)
+		}
+	} else {
+		if !service.userService.Exists(grantee) {
+			return errNotFound("grantee", grantee)
+		}
+
+		if service.authorizer.FolderPermission(grantee, *f) == PermissionOwner {
+			return newError(ErrInvalid, "grantee", grantee, "grantee already owns the folder")
+		}
+	}
+
+	grant := models.Grant{
+		FolderID:  id,
+		Grantee:   grantee,
+		Group:     isGroup,
+		Access:    access,
+		GrantedBy: sharedBy,
+		GrantedAt: time.Now(),
+	}
+
+	return service.store.PutGrant(makeGrantKey(id, grantee), grant)
+}
+
+// Unshare revokes the access of the grantee to the folder with given id.
+// If the given `unsharedBy` does not match existing users or does not own the folder, an error is returned.
+// If the folder is not shared with the grantee, an error is returned.
+func (service *ShareServiceImpl) Unshare(id int, grantee string, unsharedBy string) error {
+	defer service.locks.rlockTree()()
+	defer service.locks.lock(folderLockKey(id))()
+
+	if _, err := service.getOwnedFolder(id, unsharedBy); err != nil {
+		return err
+	}
+
+	key := makeGrantKey(id, grantee)
+	if _, exists := service.store.GetGrant(key); !exists {
+		return newError(ErrNotFound, "grant", key, "folder is not shared with the grantee")
+	}
+
+	return service.store.DeleteGrant(key)
+}
+
+// GetAll retrieves the grants of the folder with given id, ordered by grantee.
+// If the given `username` does not match existing users or does not own the folder, an error is returned.
+func (service *ShareServiceImpl) GetAll(id int, username string) ([]models.Grant, error) {
+	if _, err := service.getOwnedFolder(id, username); err != nil {
+		return nil, err
+	}
+
+	grants := make([]models.Grant, 0)
+	for _, grant := range service.store.ListGrants() {
+		if grant.FolderID == id {
+			grants = append(grants, grant)
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return strings.ToLower(grants[i].Grantee) < strings.ToLower(grants[j].Grantee)
+	})
+
+	return grants, nil
+}
+
+func (service *ShareServiceImpl) getOwnedFolder(id int, username string) (*models.Folder, error) {
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	f, err := service.folderService.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.authorizer.AuthorizeFolder(username, *f, PermissionOwner); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// makeGrantKey returns the key of the grant on the folder for the grantee, whose name is case insensitive.
+func makeGrantKey(folderID int, grantee string) string {
+	return fmt.Sprintf("%d:%s", folderID, strings.ToLower(grantee))
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+func TestShareServiceImpl_Share(t *testing.T) {
+	type fields struct {
+		folders map[int]*models.Folder
+		users   map[string]models.User
+	}
+	type args struct {
+		id       int
+		grantee  string
+		access   string
+		sharedBy string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    Permission
+		wantErr bool
+	}{
+		{
+			name: "01. it should give read access with a read-only grant.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+			},
+			args:    args{id: 1001, grantee: "Mark", access: "ro", sharedBy: "Luke"},
+			want:    PermissionRead,
+			wantErr: false,
+		},
+		{
+			name: "02. it should give write access with a read-write grant.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+			},
+			args:    args{id: 1001, grantee: "mark", access: "rw", sharedBy: "Luke"},
+			want:    PermissionWrite,
+			wantErr: false,
+		},
+		{
+			name: "03. it should return error if user does not own the folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+			},
+			args:    args{id: 1001, grantee: "Mark", access: "rw", sharedBy: "Mark"},
+			want:    PermissionNone,
+			wantErr: true,
+		},
+		{
+			name: "04. it should return error if grantee does not exist.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}},
+			},
+			args:    args{id: 1001, grantee: "Mark", access: "ro", sharedBy: "Luke"},
+			want:    PermissionNone,
+			wantErr: true,
+		},
+		{
+			name: "05. it should return error if access is invalid.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}},
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+			},
+			args:    args{id: 1001, grantee: "Mark", access: "admin", sharedBy: "Luke"},
+			want:    PermissionNone,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   tt.fields.users,
+				folders: tt.fields.folders,
+			}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &ShareServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+			}
+			if err := service.Share(tt.args.id, tt.args.grantee, tt.args.access, tt.args.sharedBy); (err != nil) != tt.wantErr {
+				t.Errorf("ShareServiceImpl.Share() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got := authorizer.FolderPermission(tt.args.grantee, *tt.fields.folders[tt.args.id]); got != tt.want {
+				t.Errorf("AuthorizerImpl.FolderPermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShareServiceImpl_Unshare(t *testing.T) {
+	folders := map[int]*models.Folder{1001: {ID: 1001, Name: "Testing", CreatedBy: "Luke"}}
+	users := map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}}
+	tests := []struct {
+		name       string
+		grants     map[string]models.Grant
+		grantee    string
+		unsharedBy string
+		wantErr    bool
+	}{
+		{
+			name:       "01. it should revoke the grant.",
+			grants:     map[string]models.Grant{"1001:mark": {FolderID: 1001, Grantee: "Mark", Access: "rw"}},
+			grantee:    "MARK",
+			unsharedBy: "Luke",
+			wantErr:    false,
+		},
+		{
+			name:       "02. it should return error if the folder is not shared with the grantee.",
+			grants:     map[string]models.Grant{},
+			grantee:    "Mark",
+			unsharedBy: "Luke",
+			wantErr:    true,
+		},
+		{
+			name:       "03. it should return error if user does not own the folder.",
+			grants:     map[string]models.Grant{"1001:mark": {FolderID: 1001, Grantee: "Mark", Access: "rw"}},
+			grantee:    "Mark",
+			unsharedBy: "Mark",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{users: users, folders: folders, grants: tt.grants}
+			authorizer := &AuthorizerImpl{store: store}
+			service := &ShareServiceImpl{
+				store:         store,
+				userService:   &UserServiceImpl{store: store},
+				folderService: &FolderServiceImpl{store: store, authorizer: authorizer},
+				authorizer:    authorizer,
+			}
+			if err := service.Unshare(1001, tt.grantee, tt.unsharedBy); (err != nil) != tt.wantErr {
+				t.Errorf("ShareServiceImpl.Unshare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && authorizer.FolderPermission(tt.grantee, *folders[1001]) != PermissionNone {
+				t.Errorf("AuthorizerImpl.FolderPermission() still grants access after unshare")
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import "virtual-file-system/internal/models"
+
+// Store is the storage backend the services are written against.
+// A store only keeps records, the business rules such as ownership and
+// name uniqueness are enforced by the services on top of it.
+type Store interface {
+	UserStore
+	FolderStore
+	FileStore
+	BlobStore
+	GrantStore
+	GroupStore
+	TrashStore
+
+	// Batch runs fn against a view of the store whose writes take effect all together once fn returns,
+	// or not at all if fn returns an error. Writes made inside fn are not visible to reads inside fn.
+	Batch(fn func(Store) error) error
+}
+
+// UserStore keeps the users of the system.
+type UserStore interface {
+	// GetUser returns the user stored under the given key.
+	GetUser(key string) (models.User, bool)
+
+	// PutUser stores the user under the given key, replacing any existing one.
+	PutUser(key string, user models.User) error
+
+	// DeleteUser removes the user stored under the given key.
+	DeleteUser(key string) error
+
+	// ListUsers returns all stored users in no particular order.
+	ListUsers() []models.User
+}
+
+// FolderStore keeps the folders of the system.
+type FolderStore interface {
+	// GetFolder returns the folder with given ID.
+	GetFolder(id int) (models.Folder, bool)
+
+	// PutFolder stores the folder under the given ID, replacing any existing one.
+	PutFolder(id int, folder models.Folder) error
+
+	// DeleteFolder removes the folder with given ID.
+	DeleteFolder(id int) error
+
+	// ListFolders returns all stored folders in no particular order.
+	ListFolders() []models.Folder
+
+	// NextFolderID reserves an unused folder ID.
+	NextFolderID() (int, error)
+}
+
+// FileStore keeps the file metadata of the system.
+type FileStore interface {
+	// GetFile returns the file with given ID.
+	GetFile(id int) (models.File, bool)
+
+	// PutFile stores the file under the given ID, replacing any existing one.
+	PutFile(id int, file models.File) error
+
+	// DeleteFile removes the file with given ID.
+	DeleteFile(id int) error
+
+	// ListFiles returns all stored files in no particular order.
+	ListFiles() []models.File
+
+	// NextFileID reserves an unused file ID.
+	NextFileID() (int, error)
+}
+
+// GrantStore keeps the folder grants of the system.
+type GrantStore interface {
+	// GetGrant returns the grant stored under the given key.
+	GetGrant(key string) (models.Grant, bool)
+
+	// PutGrant stores the grant under the given key, replacing any existing one.
+	PutGrant(key string, grant models.Grant) error
+
+	// DeleteGrant removes the grant stored under the given key.
+	DeleteGrant(key string) error
+
+	// ListGrants returns all stored grants in no particular order.
+	ListGrants() []models.Grant
+}
+
+// GroupStore keeps the user groups of the system.
+type GroupStore interface {
+	// GetGroup returns the group stored under the given key.
+	GetGroup(key string) (models.Group, bool)
+
+	// PutGroup stores the group under the given key, replacing any existing one.
+	PutGroup(key string, group models.Group) error
+
+	// DeleteGroup removes the group stored under the given key.
+	DeleteGroup(key string) error
+
+	// ListGroups returns all stored groups in no particular order.
+	ListGroups() []models.Group
+}
+
+// TrashStore keeps the deleted items of the system until they are restored or purged.
+type TrashStore interface {
+	// GetTrash returns the item with given ID.
+	GetTrash(id int) (models.TrashItem, bool)
+
+	// PutTrash stores the item under the given ID, replacing any existing one.
+	PutTrash(id int, item models.TrashItem) error
+
+	// DeleteTrash removes the item with given ID.
+	DeleteTrash(id int) error
+
+	// ListTrash returns all stored items in no particular order.
+	ListTrash() []models.TrashItem
+
+	// NextTrashID reserves an unused item ID.
+	NextTrashID() (int, error)
+}
+
+// BlobStore keeps file contents addressed by their checksum.
+type BlobStore interface {
+	// GetBlob returns the content with given checksum.
+	GetBlob(checksum string) ([]byte, bool)
+
+	// PutBlob stores the content under the given checksum.
+	PutBlob(checksum string, data []byte) error
+
+	// DeleteBlob removes the content with given checksum.
+	DeleteBlob(checksum string) error
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"virtual-file-system/internal/models"
+)
+
+// DefaultTrashRetention is how long deleted items are kept in the trash unless configured otherwise.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService is responsible for the recycle bin holding the deleted folders and files of every user.
+//
+// The methods ending in Context give up once the context is done, returning its error,
+// and act on behalf of the user carried by the context, see WithUser, when given an empty user name.
+type TrashService interface {
+	// List retrieves the items deleted by the user, and the ones deleted from the folders the user owns, ordered by ID.
+	// If the given `username` does not match existing users in the system, an error is returned.
+	List(username string) ([]models.TrashItem, error)
+
+	// ListContext is List with a context.
+	ListContext(ctx context.Context, username string) ([]models.TrashItem, error)
+
+	// Restore puts the item with given ID back where it was deleted from.
+	// If the given `restoredBy` neither deleted the item nor owns the folder it was deleted from, an error is returned.
+	// If the parent folder no longer exists or its name is taken in the meantime, or if `restoredBy`
+	// is not allowed to write it anymore, an error is returned.
+	Restore(id int, restoredBy string) error
+
+	// RestoreContext is Restore with a context.
+	RestoreContext(ctx context.Context, id int, restoredBy string) error
+
+	// Empty permanently removes the items deleted by the user.
+	// If the given `username` does not match existing users in the system, an error is returned.
+	Empty(username string) error
+
+	// EmptyContext is Empty with a context.
+	EmptyContext(ctx context.Context, username string) error
+
+	// PurgeExpired permanently removes the items kept longer than the retention period.
+	// It runs on the way of List and Restore, long running processes should call it from time to time as well.
+	PurgeExpired() error
+
+	// PurgeExpiredContext is PurgeExpired with a context.
+	PurgeExpiredContext(ctx context.Context) error
+}
+
+// TrashServiceImpl is the implementation of the TrashService interface
+type TrashServiceImpl struct {
+	store       Store
+	userService UserService
+	authorizer  Authorizer
+	locks       *lockTable
+
+	// retention is how long deleted items are kept, zero or less keeps them until the trash is emptied.
+	retention time.Duration
+}
+
+// List retrieves the items deleted by the user, and the ones deleted from the folders the user owns, ordered by ID.
+// If the given `username` does not match existing users in the system, an error is returned.
+func (service *TrashServiceImpl) List(username string) ([]models.TrashItem, error) {
+	return service.ListContext(context.Background(), username)
+}
+
+// ListContext is List with a context.
+func (service *TrashServiceImpl) ListContext(ctx context.Context, username string) ([]models.TrashItem, error) {
+	username = actingUser(ctx, username)
+	if !service.userService.Exists(username) {
+		return nil, errUnknownUser(username)
+	}
+
+	if err := service.PurgeExpiredContext(ctx); err != nil {
+		return nil, err
+	}
+
+	defer service.locks.rlockTree()()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items := make([]models.TrashItem, 0)
+	for _, item := range service.store.ListTrash() {
+		if service.isRestorableBy(item, username) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+// Restore puts the item with given ID back where it was deleted from.
+// If the given `restoredBy` neither deleted the item nor owns the folder it was deleted from, an error is returned.
+// If the parent folder no longer exists or its name is taken in the meantime, or if `restoredBy`
+// is not allowed to write it anymore, an error is returned.
+func (service *TrashServiceImpl) Restore(id int, restoredBy string) error {
+	return service.RestoreContext(context.Background(), id, restoredBy)
+}
+
+// RestoreContext is Restore with a context.
+func (service *TrashServiceImpl) RestoreContext(ctx context.Context, id int, restoredBy string) error {
+	restoredBy = actingUser(ctx, restoredBy)
+	if !service.userService.Exists(restoredBy) {
+		return errUnknownUser(restoredBy)
+	}
+
+	if err := service.PurgeExpiredContext(ctx); err != nil {
+		return err
+	}
+
+	// Putting folders back changes the shape of the hierarchy, checked for conflicts below.
+	defer service.locks.lockTree()()
+	defer service.locks.lock(trashLockKey(id))()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	item, exists := service.store.GetTrash(id)
+	if !exists || !service.isRestorableBy(item, restoredBy) {
+		return newError(ErrNotFound, "item", id, "item does not exist in the trash")
+	}
+
+	if err := service.checkRestorable(item); err != nil {
+		return err
+	}
+
+	// The access may have been revoked since the item was deleted.
+	if err := service.authorizeRestore(item, restoredBy); err != nil {
+		return err
+	}
+
+	return service.store.Batch(func(batch Store) error {
+		for _, folder := range item.Folders {
+			if err := batch.PutFolder(folder.ID, folder); err != nil {
+				return err
+			}
+		}
+
+		for _, file := range item.Files {
+			if err := batch.PutFile(file.ID, file); err != nil {
+				return err
+			}
+		}
+
+		for _, grant := range item.Grants {
+			if err := batch.PutGrant(makeGrantKey(grant.FolderID, grant.Grantee), grant); err != nil {
+				return err
+			}
+		}
+
+		return batch.DeleteTrash(id)
+	})
+}
+
+// Empty permanently removes the items deleted by the user.
+// If the given `username` does not match existing users in the system, an error is returned.
+func (service *TrashServiceImpl) Empty(username string) error {
+	return service.EmptyContext(context.Background(), username)
+}
+
+// EmptyContext is Empty with a context.
+func (service *TrashServiceImpl) EmptyContext(ctx context.Context, username string) error {
+	username = actingUser(ctx, username)
+	if !service.userService.Exists(username) {
+		return errUnknownUser(username)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range service.itemsOf(username) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := purgeTrash(service.store, service.locks, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired permanently removes the items kept longer than the retention period.
+func (service *TrashServiceImpl) PurgeExpired() error {
+	return service.PurgeExpiredContext(context.Background())
+}
+
+// PurgeExpiredContext is PurgeExpired with a context.
+func (service *TrashServiceImpl) PurgeExpiredContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if service.retention <= 0 {
+		return nil
+	}
+
+	expiry := time.Now().Add(-service.retention)
+	for _, item := range service.store.ListTrash() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if item.DeletedAt.Before(expiry) {
+			if err := purgeTrash(service.store, service.locks, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// itemsOf returns the items deleted by the user.
+func (service *TrashServiceImpl) itemsOf(username string) []models.TrashItem {
+	items := make([]models.TrashItem, 0)
+	for _, item := range service.store.ListTrash() {
+		if strings.EqualFold(item.DeletedBy, username) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// isRestorableBy returns true if the user deleted the item, or owns the folder it was deleted from.
+func (service *TrashServiceImpl) isRestorableBy(item models.TrashItem, username string) bool {
+	if strings.EqualFold(item.DeletedBy, username) {
+		return true
+	}
+
+	parentID := parentOf(item)
+	if parentID == 0 {
+		return strings.EqualFold(item.Folders[0].CreatedBy, username)
+	}
+
+	parent, exists := service.store.GetFolder(parentID)
+
+	return exists && service.authorizer.FolderPermission(username, parent) == PermissionOwner
+}
+
+// authorizeRestore returns an error if the user is not allowed to write the folder the item goes back to.
+// Only the owner of a folder at the root can put it back there.
+func (service *TrashServiceImpl) authorizeRestore(item models.TrashItem, restoredBy string) error {
+	parentID := parentOf(item)
+	if parentID == 0 {
+		if !strings.EqualFold(item.Folders[0].CreatedBy, restoredBy) {
+			return newError(ErrPermissionDenied, "item", item.ID, "permission denied: only the owner can restore the folder to the root")
+		}
+
+		return nil
+	}
+
+	parent, exists := service.store.GetFolder(parentID)
+	if !exists {
+		return newError(ErrConflict, "folder", parentID, "the parent folder no longer exists, restore it first")
+	}
+
+	return service.authorizer.AuthorizeFolder(restoredBy, parent, PermissionWrite)
+}
+
+// parentOf returns the ID of the folder the item was deleted from, 0 for a folder at the root.
+func parentOf(item models.TrashItem) int {
+	if len(item.Folders) > 0 {
+		return item.Folders[0].ParentID
+	}
+
+	return item.Files[0].FolderID
+}
+
+// checkRestorable returns an error if the item cannot be put back where it was deleted from.
+func (service *TrashServiceImpl) checkRestorable(item models.TrashItem) error {
+	if len(item.Folders) > 0 {
+		root := item.Folders[0]
+		if root.ParentID != 0 {
+			if _, exists := service.store.GetFolder(root.ParentID); !exists {
+				return newError(ErrConflict, "folder", root.ParentID, "the parent folder no longer exists, restore it first")
+			}
+		}
+
+		for _, f := range service.store.ListFolders() {
+			if f.ParentID != root.ParentID || !strings.EqualFold(f.Name, root.Name) {
+				continue
+			}
+
+			if root.ParentID == 0 && !strings.EqualFold(f.CreatedBy, root.CreatedBy) {
+				continue
+			}
+
+			return newError(ErrAlreadyExists, "folder", root.Name, "folder name already exists")
+		}
+
+		return nil
+	}
+
+	for _, file := range item.Files {
+		if _, exists := service.store.GetFolder(file.FolderID); !exists {
+			return newError(ErrConflict, "folder", file.FolderID, "the parent folder no longer exists, restore it first")
+		}
+
+		for _, f := range service.store.ListFiles() {
+			if f.FolderID == file.FolderID && strings.EqualFold(f.Name, file.Name) {
+				return newError(ErrAlreadyExists, "file", file.Name, "file already exists")
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveToTrash stores the item in the trash and removes its folders, files and grants from the system,
+// all together in a single batch.
+func moveToTrash(store Store, item models.TrashItem) error {
+	id, err := store.NextTrashID()
+	if err != nil {
+		return err
+	}
+
+	item.ID = id
+	item.DeletedAt = time.Now()
+
+	return store.Batch(func(batch Store) error {
+		if err := batch.PutTrash(id, item); err != nil {
+			return err
+		}
+
+		for _, grant := range item.Grants {
+			if err := batch.DeleteGrant(makeGrantKey(grant.FolderID, grant.Grantee)); err != nil {
+				return err
+			}
+		}
+
+		for _, file := range item.Files {
+			if err := batch.DeleteFile(file.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, folder := range item.Folders {
+			if err := batch.DeleteFolder(folder.ID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// purgeTrash permanently removes the item from the trash, together with the contents only it refers to.
+// Nothing happens if the item was restored or purged in the meantime.
+func purgeTrash(store Store, locks *lockTable, item models.TrashItem) error {
+	defer locks.lock(trashLockKey(item.ID))()
+
+	if _, exists := store.GetTrash(item.ID); !exists {
+		return nil
+	}
+
+	if err := store.DeleteTrash(item.ID); err != nil {
+		return err
+	}
+
+	for _, file := range item.Files {
+		for _, v := range versionsOf(file) {
+			if err := releaseBlob(store, locks, v.Checksum); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"virtual-file-system/internal/models"
+)
+
+func TestTrashServiceImpl_Restore(t *testing.T) {
+	type fields struct {
+		folders map[int]*models.Folder
+		files   map[int]models.File
+		grants  map[string]models.Grant
+		trash   map[int]models.TrashItem
+	}
+	type args struct {
+		id         int
+		restoredBy string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "01. it should restore the folder together with its files.",
+			fields: fields{
+				folders: map[int]*models.Folder{},
+				files:   map[int]models.File{},
+				trash: map[int]models.TrashItem{
+					1: {
+						ID:        1,
+						Folders:   []models.Folder{{ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+						Files:     []models.File{{ID: 1, Name: "1.tc", FolderID: 1001}},
+						DeletedBy: "Luke",
+					},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Luke",
+			},
+			wantErr: false,
+		},
+		{
+			name: "02. it should return error if the item was deleted by another user.",
+			fields: fields{
+				folders: map[int]*models.Folder{},
+				files:   map[int]models.File{},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Folders: []models.Folder{{ID: 1001, Name: "Work", CreatedBy: "Luke"}}, DeletedBy: "Luke"},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Mark",
+			},
+			wantErr: true,
+		},
+		{
+			name: "03. it should return error if the parent folder no longer exists.",
+			fields: fields{
+				folders: map[int]*models.Folder{},
+				files:   map[int]models.File{},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Files: []models.File{{ID: 1, Name: "1.tc", FolderID: 1001}}, DeletedBy: "Luke"},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "04. it should return error if the name is taken in the meantime.",
+			fields: fields{
+				folders: map[int]*models.Folder{1002: {ID: 1002, Name: "work", CreatedBy: "Luke"}},
+				files:   map[int]models.File{},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Folders: []models.Folder{{ID: 1001, Name: "Work", CreatedBy: "Luke"}}, DeletedBy: "Luke"},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Luke",
+			},
+			wantErr: true,
+		},
+		{
+			name: "05. it should restore the file deleted by another user from a folder the user owns.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+				files:   map[int]models.File{},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Files: []models.File{{ID: 1, Name: "1.tc", FolderID: 1001, CreatedBy: "Mark"}}, DeletedBy: "Mark"},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Luke",
+			},
+			wantErr: false,
+		},
+		{
+			name: "06. it should return error if the user is no longer allowed to write the folder.",
+			fields: fields{
+				folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+				files:   map[int]models.File{},
+				grants:  map[string]models.Grant{makeGrantKey(1001, "Mark"): {FolderID: 1001, Grantee: "Mark", Access: models.AccessReadOnly}},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Files: []models.File{{ID: 1, Name: "1.tc", FolderID: 1001, CreatedBy: "Luke"}}, DeletedBy: "Mark"},
+				},
+			},
+			args: args{
+				id:         1,
+				restoredBy: "Mark",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+				folders: tt.fields.folders,
+				files:   tt.fields.files,
+				grants:  tt.fields.grants,
+				trash:   tt.fields.trash,
+			}
+			service := &TrashServiceImpl{
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				authorizer:  &AuthorizerImpl{store: store},
+			}
+			if err := service.Restore(tt.args.id, tt.args.restoredBy); (err != nil) != tt.wantErr {
+				t.Errorf("TrashServiceImpl.Restore() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			_, inTrash := store.trash[tt.args.id]
+			if inTrash != tt.wantErr {
+				t.Errorf("TrashServiceImpl.Restore() left the item in the trash = %v, want %v", inTrash, tt.wantErr)
+			}
+
+			if !tt.wantErr {
+				for _, file := range tt.fields.trash[tt.args.id].Files {
+					if _, exists := store.files[file.ID]; !exists {
+						t.Errorf("TrashServiceImpl.Restore() did not restore file %d", file.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTrashServiceImpl_List(t *testing.T) {
+	store := &MemoryStore{
+		users:   map[string]models.User{"luke": {Name: "Luke"}, "mark": {Name: "Mark"}},
+		folders: map[int]*models.Folder{1001: {ID: 1001, Name: "Work", CreatedBy: "Luke"}},
+		trash: map[int]models.TrashItem{
+			1: {ID: 1, Files: []models.File{{ID: 1, Name: "1.tc", FolderID: 1001}}, DeletedBy: "Mark"},
+			2: {ID: 2, Folders: []models.Folder{{ID: 1002, Name: "Temp", CreatedBy: "Mark"}}, DeletedBy: "Mark"},
+		},
+	}
+	service := &TrashServiceImpl{
+		store:       store,
+		userService: &UserServiceImpl{store: store},
+		authorizer:  &AuthorizerImpl{store: store},
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		wantIDs  []int
+	}{
+		{
+			name:     "01. it should list the items deleted by the user.",
+			username: "Mark",
+			wantIDs:  []int{1, 2},
+		},
+		{
+			name:     "02. it should list the items deleted by others from the folders the user owns.",
+			username: "Luke",
+			wantIDs:  []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := service.List(tt.username)
+			if err != nil {
+				t.Fatalf("TrashServiceImpl.List() error = %v", err)
+			}
+
+			var ids []int
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("TrashServiceImpl.List() = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestTrashServiceImpl_PurgeExpired(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		deletedAt time.Time
+		wantKept  bool
+	}{
+		{
+			name:      "01. it should purge the items kept longer than the retention period.",
+			retention: time.Hour,
+			deletedAt: time.Now().Add(-2 * time.Hour),
+			wantKept:  false,
+		},
+		{
+			name:      "02. it should keep the items within the retention period.",
+			retention: time.Hour,
+			deletedAt: time.Now(),
+			wantKept:  true,
+		},
+		{
+			name:      "03. it should keep every item without a retention period.",
+			deletedAt: time.Now().Add(-2 * time.Hour),
+			wantKept:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &MemoryStore{
+				users: map[string]models.User{"luke": {Name: "Luke"}},
+				blobs: map[string][]byte{"abc": []byte("hello")},
+				trash: map[int]models.TrashItem{
+					1: {ID: 1, Files: []models.File{{ID: 1, Name: "1.tc", Checksum: "abc"}}, DeletedBy: "Luke", DeletedAt: tt.deletedAt},
+				},
+			}
+			service := &TrashServiceImpl{
+				store:       store,
+				userService: &UserServiceImpl{store: store},
+				retention:   tt.retention,
+			}
+			if err := service.PurgeExpired(); err != nil {
+				t.Errorf("TrashServiceImpl.PurgeExpired() error = %v", err)
+				return
+			}
+
+			_, kept := store.trash[1]
+			_, blobKept := store.blobs["abc"]
+			if kept != tt.wantKept || blobKept != tt.wantKept {
+				t.Errorf("TrashServiceImpl.PurgeExpired() kept item = %v, blob = %v, want %v", kept, blobKept, tt.wantKept)
+			}
+		})
+	}
+}
@@ -1,48 +1,335 @@
 package services
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"virtual-file-system/internal/models"
 )
 
 // UserService is responsible for CRUD operations against a user
+//
+// The methods ending in Context give up once the context is done, returning its error.
 type UserService interface {
 	// Register adds a user to the system.
+	// Unless the password is empty, it is stored as a salted hash and the user has to log in with it.
 	// If user already exists, an error is returned.
-	Register(name string) error
+	Register(name string, password string) error
+
+	// RegisterContext is Register, with a context.
+	RegisterContext(ctx context.Context, name string, password string) error
+
+	// Authenticate verifies the password of the user.
+	// If the user does not exist or the password does not match, an error is returned.
+	Authenticate(name string, password string) error
+
+	// AuthenticateContext is Authenticate, with a context.
+	AuthenticateContext(ctx context.Context, name string, password string) error
+
+	// ChangePassword replaces the password of the user, an empty one lets the user log in without any.
+	// If the given `changedBy` is not the user, an error is returned.
+	ChangePassword(name string, password string, changedBy string) error
+
+	// ChangePasswordContext is ChangePassword with a context.
+	ChangePasswordContext(ctx context.Context, name string, password string, changedBy string) error
+
+	// Delete removes the user from the system, together with the groups they created, their group memberships,
+	// the folders shared with them and the items they deleted.
+	// If the given `deletedBy` is not the user, an error is returned.
+	// If the user still owns folders, an error is returned, they have to be deleted first.
+	Delete(name string, deletedBy string) error
+
+	// DeleteContext is Delete with a context.
+	DeleteContext(ctx context.Context, name string, deletedBy string) error
+
+	// HasPassword returns true if the given user registered with a password.
+	HasPassword(username string) bool
+
+	// HasPasswordContext is HasPassword with a context.
+	HasPasswordContext(ctx context.Context, username string) (bool, error)
 
 	// Exists returns true if the given user name exists in the internal user storage.
 	// The user name comparison is case insensitive
 	Exists(username string) bool
+
+	// ExistsContext is Exists with a context.
+	ExistsContext(ctx context.Context, username string) (bool, error)
+
+	// Names returns the names of the users in alphabetical order.
+	Names() []string
+
+	// NamesContext is Names with a context.
+	NamesContext(ctx context.Context) ([]string, error)
 }
 
 // UserServiceImpl is the implementation of the UserService interface
 type UserServiceImpl struct {
-	users map[string]models.User
+	store Store
+	locks *lockTable
 }
 
 // Register adds a user to the system.
+// Unless the password is empty, it is stored as a salted hash and the user has to log in with it.
 // If user already exists, an error is returned.
-func (service *UserServiceImpl) Register(name string) error {
+func (service *UserServiceImpl) Register(name string, password string) error {
+	return service.RegisterContext(context.Background(), name, password)
+}
+
+// RegisterContext is Register, with a context.
+func (service *UserServiceImpl) RegisterContext(ctx context.Context, name string, password string) error {
+	if strings.TrimSpace(name) == "" || strings.HasPrefix(name, GroupPrefix) {
+		return newError(ErrInvalid, "user", name, fmt.Sprintf("user name should not be empty or start with %q", GroupPrefix))
+	}
+
+	defer service.locks.lock(userLockKey(name))()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if service.Exists(name) {
-		return errors.New("user already exists")
+		return newError(ErrAlreadyExists, "user", name, "user already exists")
+	}
+
+	user := models.User{Name: name}
+	if password != "" {
+		salt, err := newPasswordSalt()
+		if err != nil {
+			return err
+		}
+
+		user.PasswordSalt = salt
+		user.PasswordHash = hashPassword(password, salt)
 	}
 
 	key := service.makeKey(name)
-	service.users[key] = models.User{Name: name}
+	return service.store.PutUser(key, user)
+}
+
+// Authenticate verifies the password of the user.
+// If the user does not exist or the password does not match, an error is returned.
+func (service *UserServiceImpl) Authenticate(name string, password string) error {
+	return service.AuthenticateContext(context.Background(), name, password)
+}
+
+// AuthenticateContext is Authenticate, with a context.
+func (service *UserServiceImpl) AuthenticateContext(ctx context.Context, name string, password string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	user, exists := service.store.GetUser(service.makeKey(name))
+	if !exists {
+		return newError(ErrUnauthenticated, "user", name, "authentication failed")
+	}
+
+	// Users registered without a password can only log in without one.
+	if user.PasswordHash == "" {
+		if password != "" {
+			return newError(ErrUnauthenticated, "user", name, "authentication failed")
+		}
+		return nil
+	}
+
+	if !verifyPassword(password, user.PasswordSalt, user.PasswordHash) {
+		return newError(ErrUnauthenticated, "user", name, "authentication failed")
+	}
+
 	return nil
 }
 
+// ChangePassword replaces the password of the user, an empty one lets the user log in without any.
+// If the given `changedBy` is not the user, an error is returned.
+func (service *UserServiceImpl) ChangePassword(name string, password string, changedBy string) error {
+	return service.ChangePasswordContext(context.Background(), name, password, changedBy)
+}
+
+// ChangePasswordContext is ChangePassword with a context.
+func (service *UserServiceImpl) ChangePasswordContext(ctx context.Context, name string, password string, changedBy string) error {
+	changedBy = actingUser(ctx, changedBy)
+
+	defer service.locks.lock(userLockKey(name))()
+
+	user, err := service.getOwnUser(name, changedBy)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	user.PasswordSalt, user.PasswordHash = "", ""
+	if password != "" {
+		salt, err := newPasswordSalt()
+		if err != nil {
+			return err
+		}
+
+		user.PasswordSalt = salt
+		user.PasswordHash = hashPassword(password, salt)
+	}
+
+	return service.store.PutUser(service.makeKey(name), *user)
+}
+
+// Delete removes the user from the system, together with the groups they created, their group memberships,
+// the folders shared with them and the items they deleted.
+// If the given `deletedBy` is not the user, an error is returned.
+// If the user still owns folders, an error is returned, they have to be deleted first.
+func (service *UserServiceImpl) Delete(name string, deletedBy string) error {
+	return service.DeleteContext(context.Background(), name, deletedBy)
+}
+
+// DeleteContext is Delete with a context.
+func (service *UserServiceImpl) DeleteContext(ctx context.Context, name string, deletedBy string) error {
+	deletedBy = actingUser(ctx, deletedBy)
+
+	// Nobody may create folders or share them with the user meanwhile.
+	defer service.locks.lockTree()()
+	defer service.locks.lock(userLockKey(name))()
+
+	user, err := service.getOwnUser(name, deletedBy)
+	if err != nil {
+		return err
+	}
+
+	for _, folder := range service.store.ListFolders() {
+		if strings.EqualFold(folder.CreatedBy, name) {
+			return newError(ErrConflict, "user", name, "user still owns folders, delete them first")
+		}
+	}
+
+	groups := service.store.ListGroups()
+	groupKeys := make([]string, len(groups))
+	for i, group := range groups {
+		groupKeys[i] = groupLockKey(group.Name)
+	}
+	defer service.locks.lock(groupKeys...)()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// A user registering under the same name later on must not get the items back.
+	for _, item := range service.store.ListTrash() {
+		if strings.EqualFold(item.DeletedBy, name) {
+			if err := purgeTrash(service.store, service.locks, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return service.store.Batch(func(batch Store) error {
+		// The grants given to the user and to the groups going away with them.
+		grantees := map[string]bool{strings.ToLower(user.Name): true}
+		for _, group := range groups {
+			if strings.EqualFold(group.CreatedBy, name) {
+				grantees[strings.ToLower(GroupPrefix+group.Name)] = true
+				if err := batch.DeleteGroup(makeGroupKey(group.Name)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if isGroupMember(group, name) {
+				members := make([]string, 0, len(group.Members))
+				for _, m := range group.Members {
+					if !strings.EqualFold(m, name) {
+						members = append(members, m)
+					}
+				}
+				group.Members = members
+
+				if err := batch.PutGroup(makeGroupKey(group.Name), group); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, grant := range service.store.ListGrants() {
+			if grantees[strings.ToLower(grant.Grantee)] {
+				if err := batch.DeleteGrant(makeGrantKey(grant.FolderID, grant.Grantee)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return batch.DeleteUser(service.makeKey(name))
+	})
+}
+
+// HasPassword returns true if the given user registered with a password.
+func (service *UserServiceImpl) HasPassword(username string) bool {
+	user, exists := service.store.GetUser(service.makeKey(username))
+
+	return exists && user.PasswordHash != ""
+}
+
+// HasPasswordContext is HasPassword with a context.
+func (service *UserServiceImpl) HasPasswordContext(ctx context.Context, username string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return service.HasPassword(username), nil
+}
+
 // Exists returns true if the given user name exists in the internal user storage.
 // The user name comparison is case insensitive
 func (service *UserServiceImpl) Exists(username string) bool {
 	key := service.makeKey(username)
-	_, exists := service.users[key]
+	_, exists := service.store.GetUser(key)
 
 	return exists
 }
 
+// ExistsContext is Exists with a context.
+func (service *UserServiceImpl) ExistsContext(ctx context.Context, username string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return service.Exists(username), nil
+}
+
+// Names returns the names of the users in alphabetical order.
+func (service *UserServiceImpl) Names() []string {
+	users := service.store.ListUsers()
+
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NamesContext is Names with a context.
+func (service *UserServiceImpl) NamesContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return service.Names(), nil
+}
+
+// getOwnUser returns the user, as long as the given `username` is the user.
+func (service *UserServiceImpl) getOwnUser(name string, username string) (*models.User, error) {
+	user, exists := service.store.GetUser(service.makeKey(name))
+	if !exists {
+		return nil, errNotFound("user", name)
+	}
+
+	if !strings.EqualFold(name, username) {
+		return nil, newError(ErrPermissionDenied, "user", name, "permission denied: only the user can change their account")
+	}
+
+	return &user, nil
+}
+
 func (service *UserServiceImpl) makeKey(username string) string {
 	return strings.ToLower(username)
 }
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"virtual-file-system/internal/models"
 )
@@ -50,11 +53,185 @@ func TestUserServiceImpl_Register(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			service := &UserServiceImpl{
-				users: tt.fields.users,
+				store: &MemoryStore{users: tt.fields.users},
 			}
-			if err := service.Register(tt.args.name); (err != nil) != tt.wantErr {
+			if err := service.Register(tt.args.name, ""); (err != nil) != tt.wantErr {
 				t.Errorf("UserServiceImpl.Register() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestUserServiceImpl_Authenticate(t *testing.T) {
+	service := &UserServiceImpl{
+		store: &MemoryStore{users: map[string]models.User{}},
+	}
+	if err := service.Register("Luke", "secret"); err != nil {
+		t.Fatalf("UserServiceImpl.Register() error = %v", err)
+	}
+	if err := service.Register("Mark", ""); err != nil {
+		t.Fatalf("UserServiceImpl.Register() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{name: "01. it should accept the registered password.", username: "luke", password: "secret", wantErr: false},
+		{name: "02. it should return error for a wrong password.", username: "Luke", password: "Secret", wantErr: true},
+		{name: "03. it should return error for an unknown user.", username: "April", password: "secret", wantErr: true},
+		{name: "04. it should accept an empty password for users registered without one.", username: "Mark", password: "", wantErr: false},
+		{name: "05. it should return error for a password given to users registered without one.", username: "Mark", password: "secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.Authenticate(tt.username, tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("UserServiceImpl.Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if user, _ := service.store.GetUser("luke"); user.PasswordHash == "" || user.PasswordHash == "secret" || user.PasswordSalt == "" {
+		t.Errorf("UserServiceImpl.Register() should store a salted hash, got %+v", user)
+	}
+	if !service.HasPassword("LUKE") || service.HasPassword("Mark") {
+		t.Errorf("UserServiceImpl.HasPassword() does not reflect the registration")
+	}
+}
+
+func TestUserServiceImpl_Names(t *testing.T) {
+	service := &UserServiceImpl{
+		store: &MemoryStore{users: map[string]models.User{
+			"mark": {Name: "Mark"},
+			"luke": {Name: "Luke"},
+		}},
+	}
+	if got, want := service.Names(), []string{"Luke", "Mark"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UserServiceImpl.Names() = %v, want %v", got, want)
+	}
+}
+
+func TestUserServiceImpl_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		changedBy string
+		wantErr   error
+		login     string
+	}{
+		{
+			name:      "01. it should replace the password of the user.",
+			password:  "new",
+			changedBy: "luke",
+			login:     "new",
+		},
+		{
+			name:      "02. it should let the user log in without a password once it is emptied.",
+			changedBy: "Luke",
+		},
+		{
+			name:      "03. it should return error if another user changes the password.",
+			password:  "new",
+			changedBy: "mark",
+			wantErr:   ErrPermissionDenied,
+			login:     "secret",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &UserServiceImpl{store: &MemoryStore{}}
+			service.Register("Luke", "secret")
+			service.Register("Mark", "")
+
+			if err := service.ChangePassword("luke", tt.password, tt.changedBy); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UserServiceImpl.ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err := service.Authenticate("luke", tt.login); err != nil {
+				t.Errorf("UserServiceImpl.Authenticate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUserServiceImpl_Delete(t *testing.T) {
+	tests := []struct {
+		name      string
+		deletedBy string
+		prepare   func(factory *Factory)
+		wantErr   error
+	}{
+		{
+			name:      "01. it should remove the user.",
+			deletedBy: "mark",
+		},
+		{
+			name:      "02. it should return error if another user deletes the user.",
+			deletedBy: "luke",
+			wantErr:   ErrPermissionDenied,
+		},
+		{
+			name:      "03. it should return error while the user still owns folders.",
+			deletedBy: "mark",
+			prepare: func(factory *Factory) {
+				factory.GetFolderService().Create("Home", "mark", "")
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name:      "04. it should take back the folders shared with the user and the groups they created or belong to.",
+			deletedBy: "mark",
+			prepare: func(factory *Factory) {
+				factory.GetGroupService().Create("squad", "mark")
+				factory.GetGroupService().Create("team", "luke")
+				factory.GetGroupService().AddMember("team", "mark", "luke")
+				factory.GetShareService().Share(1001, "mark", "rw", "luke")
+				factory.GetShareService().Share(1001, "@squad", "ro", "luke")
+			},
+		},
+		{
+			name:      "05. it should empty the trash of the user.",
+			deletedBy: "mark",
+			prepare: func(factory *Factory) {
+				factory.GetShareService().Share(1001, "mark", "rw", "luke")
+				factory.GetFileService().Upload("mark", 1001, "1.tc", "", strings.NewReader("hello"))
+				factory.GetFileService().Delete("mark", 1001, "1.tc")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			factory.GetUserService().Register("luke", "")
+			factory.GetUserService().Register("mark", "")
+			factory.GetFolderService().Create("Work", "luke", "")
+			if tt.prepare != nil {
+				tt.prepare(factory)
+			}
+
+			if err := factory.GetUserService().Delete("Mark", tt.deletedBy); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UserServiceImpl.Delete() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// Registering again under the same name starts afresh.
+			factory.GetUserService().Register("mark", "")
+			if grants, _ := factory.GetShareService().GetAll(1001, "luke"); len(grants) != 0 {
+				t.Errorf("ShareService.GetAll() = %v, want no grants", grants)
+			}
+			if groups := factory.GetGroupService().GetByMember("mark"); len(groups) != 0 {
+				t.Errorf("GroupService.GetByMember() = %v, want no groups", groups)
+			}
+			if factory.GetGroupService().Exists("squad") {
+				t.Errorf("GroupService.Exists() = true, want the group of the user to be deleted")
+			}
+			if items, _ := factory.GetTrashService().List("mark"); len(items) != 0 {
+				t.Errorf("TrashService.List() = %v, want no items", items)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"virtual-file-system/internal/models"
+)
+
+// Operations recorded in the write-ahead log.
+const (
+	opPutUser      = "put_user"
+	opDeleteUser   = "delete_user"
+	opPutFolder    = "put_folder"
+	opDeleteFolder = "delete_folder"
+	opNextFolderID = "next_folder_id"
+	opPutFile      = "put_file"
+	opDeleteFile   = "delete_file"
+	opNextFileID   = "next_file_id"
+	opPutBlob      = "put_blob"
+	opDeleteBlob   = "delete_blob"
+	opPutGrant     = "put_grant"
+	opDeleteGrant  = "delete_grant"
+	opPutGroup     = "put_group"
+	opDeleteGroup  = "delete_group"
+	opPutTrash     = "put_trash"
+	opDeleteTrash  = "delete_trash"
+	opNextTrashID  = "next_trash_id"
+	opBatch        = "batch"
+)
+
+// walRecord is a single mutating operation against the store.
+type walRecord struct {
+	Op     string            `json:"op"`
+	Key    string            `json:"key,omitempty"`
+	ID     int               `json:"id,omitempty"`
+	User   *models.User      `json:"user,omitempty"`
+	Folder *models.Folder    `json:"folder,omitempty"`
+	File   *models.File      `json:"file,omitempty"`
+	Data   []byte            `json:"data,omitempty"`
+	Grant  *models.Grant     `json:"grant,omitempty"`
+	Group  *models.Group     `json:"group,omitempty"`
+	Trash  *models.TrashItem `json:"trash,omitempty"`
+
+	// Records are the operations of a batch, which are applied all together.
+	Records []walRecord `json:"records,omitempty"`
+}
+
+// wal is an append-only log of records, one per line, each prefixed by the CRC-32 of its payload.
+//
+//	<crc32 in hex> <json payload>\n
+type wal struct {
+	file walFile
+}
+
+// walFile is the file the log is kept in, an *os.File.
+type walFile interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// openWAL opens the log at path, creating it if needed.
+// Every complete record is passed to apply in order. A torn or corrupted record at the
+// end of the log, as left behind by a crash in the middle of an append, is truncated away.
+// Corruption before the last record cannot be recovered from and is reported as an error.
+func openWAL(path string, apply func(walRecord) error) (*wal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := replayWAL(file, apply)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &wal{file: file}, nil
+}
+
+// replayWAL applies the records in r and returns the offset right after the last good one.
+func replayWAL(r io.Reader, apply func(walRecord) error) (int64, error) {
+	reader := bufio.NewReader(r)
+
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Anything without a trailing newline is a torn write.
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		record, ok := decodeWALRecord(line)
+		if !ok {
+			if _, err := reader.Peek(1); err == io.EOF {
+				return offset, nil
+			}
+			return offset, fmt.Errorf("corrupted write-ahead log record at offset %d", offset)
+		}
+
+		if err := apply(record); err != nil {
+			return offset, err
+		}
+
+		offset += int64(len(line))
+	}
+}
+
+// append durably writes the record to the end of the log.
+// A record written partway is truncated away, so that the records appended next do not follow a torn one.
+func (log *wal) append(record walRecord) error {
+	line, err := encodeWALRecord(record)
+	if err != nil {
+		return err
+	}
+
+	offset, err := log.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, writeErr := log.file.Write(line); writeErr != nil {
+		if err := log.file.Truncate(offset); err != nil {
+			return err
+		}
+
+		if _, err := log.file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		return writeErr
+	}
+
+	return log.file.Sync()
+}
+
+// reset empties the log once its records are captured by a snapshot.
+func (log *wal) reset() error {
+	if err := log.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := log.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return log.file.Sync()
+}
+
+func (log *wal) close() error {
+	return log.file.Close()
+}
+
+func encodeWALRecord(record walRecord) ([]byte, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	line := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)
+	return []byte(line), nil
+}
+
+func decodeWALRecord(line []byte) (walRecord, bool) {
+	var record walRecord
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	sep := bytes.IndexByte(line, ' ')
+	if sep < 0 {
+		return record, false
+	}
+
+	var sum uint32
+	if _, err := fmt.Sscanf(string(line[:sep]), "%08x", &sum); err != nil {
+		return record, false
+	}
+
+	payload := line[sep+1:]
+	if crc32.ChecksumIEEE(payload) != sum {
+		return record, false
+	}
+
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, false
+	}
+
+	return record, true
+}
+
+// errUnknownWALOp is returned when replaying a record this version does not understand.
+var errUnknownWALOp = errors.New("unknown write-ahead log operation")
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"virtual-file-system/internal/models"
+)
+
+// tornFile writes half of the next record it is given before failing, as a full disk would.
+type tornFile struct {
+	*os.File
+	fail bool
+}
+
+func (f *tornFile) Write(p []byte) (int, error) {
+	if !f.fail {
+		return f.File.Write(p)
+	}
+
+	f.fail = false
+	n, _ := f.File.Write(p[:len(p)/2])
+
+	return n, errors.New("no space left on device")
+}
+
+func TestWAL_Append(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, walFileName)
+	log, err := openWAL(path, func(walRecord) error { return nil })
+	if err != nil {
+		t.Fatalf("openWAL() error = %v", err)
+	}
+	file := &tornFile{File: log.file.(*os.File)}
+	log.file = file
+
+	luke := walRecord{Op: opPutUser, Key: "luke", User: &models.User{Name: "Luke"}}
+	if err := log.append(luke); err != nil {
+		t.Fatalf("wal.append() error = %v", err)
+	}
+
+	file.fail = true
+	if err := log.append(walRecord{Op: opPutUser, Key: "john", User: &models.User{Name: "John"}}); err == nil {
+		t.Fatalf("wal.append() error = nil, want the write error")
+	}
+
+	mark := walRecord{Op: opPutUser, Key: "mark", User: &models.User{Name: "Mark"}}
+	if err := log.append(mark); err != nil {
+		t.Fatalf("wal.append() error = %v", err)
+	}
+	log.close()
+
+	var keys []string
+	reopened, err := openWAL(path, func(record walRecord) error {
+		keys = append(keys, record.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("openWAL() error = %v, want the torn record to be gone", err)
+	}
+	defer reopened.close()
+
+	if len(keys) != 2 || keys[0] != "luke" || keys[1] != "mark" {
+		t.Errorf("openWAL() replayed %v, want [luke mark]", keys)
+	}
+}
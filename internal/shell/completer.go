@@ -0,0 +1,201 @@
+package shell
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"virtual-file-system/internal/actions"
+
+	"github.com/google/shlex"
+)
+
+// Completer completes the commands of the shell and their arguments from the live file system:
+// user names, folder IDs and paths, file names and trash items.
+type Completer struct {
+	factory *actions.Factory
+}
+
+// NewCompleter returns a completer for the commands created by the factory,
+// which tells the user logged in to the shell.
+func NewCompleter(factory *actions.Factory) *Completer {
+	return &Completer{factory: factory}
+}
+
+// Complete returns the words that may replace the last word of head, which is the line up to the cursor:
+// a command name for the first word, or else a word fitting the argument being typed, as described by the command.
+func (c *Completer) Complete(head string) []string {
+	args, err := shlex.Split(head)
+	if err != nil {
+		return nil
+	}
+
+	// The word being typed is empty after a space.
+	if len(args) == 0 || strings.LastIndexFunc(head, unicode.IsSpace) == len(head)-1 {
+		args = append(args, "")
+	}
+
+	if len(args) == 1 {
+		return commandNames()
+	}
+
+	cmd := actions.LookupCommand(args[0])
+	if cmd == nil {
+		return nil
+	}
+
+	// The words given so far are bound the way the command does.
+	username := c.factory.SessionUser()
+	b := cmd.Bind(args[1:len(args)-1], username == "")
+
+	if cmd.User && len(b.Specs) > len(cmd.Args) && b.Given[0] {
+		// The command refuses a user protected by a password unless logged in, so nothing of theirs is completed.
+		if c.factory.Client.HasPassword(b.Values[0]) {
+			return nil
+		}
+		username = b.Values[0]
+	}
+
+	word := args[len(args)-1]
+	if i := strings.Index(word, "="); strings.HasPrefix(word, "--") && i >= 0 {
+		j := b.Index(word[:i])
+		if j < 0 {
+			return nil
+		}
+
+		var words []string
+		for _, value := range c.values(b, j, username) {
+			words = append(words, word[:i+1]+value)
+		}
+		return words
+	}
+
+	if strings.HasPrefix(word, "-") {
+		var names []string
+		for j, spec := range b.Specs {
+			if spec.Flag != "" && !b.Given[j] {
+				names = append(names, spec.Flag+"=")
+			}
+		}
+		for _, flag := range cmd.Flags {
+			names = append(names, flag.Name)
+		}
+		return names
+	}
+
+	if b.Next < 0 {
+		return nil
+	}
+
+	return c.values(b, b.Next, username)
+}
+
+// values returns the words the j-th argument may be, given the values of the arguments bound so far.
+func (c *Completer) values(b actions.Binding, j int, username string) []string {
+	arg := b.Specs[j]
+	if len(arg.Choices) > 0 {
+		return arg.Choices
+	}
+
+	switch arg.Kind {
+	case actions.ArgUser:
+		return c.factory.Client.Users()
+	case actions.ArgFolder:
+		return c.folders(username)
+	case actions.ArgFolderPath:
+		return c.ownFolderPaths(username)
+	case actions.ArgFile:
+		// The files are the ones of the folder given by the closest folder argument before.
+		for i := j - 1; i >= 0; i-- {
+			if b.Specs[i].Kind == actions.ArgFolder && b.Given[i] {
+				return c.files(username, b.Values[i])
+			}
+		}
+	case actions.ArgTrashItem:
+		return c.trashItems(username)
+	case actions.ArgCommand:
+		return commandNames()
+	}
+
+	return nil
+}
+
+// commandNames returns the names and the aliases of the commands.
+func commandNames() []string {
+	var names []string
+	for _, cmd := range actions.Commands() {
+		names = append(names, cmd.Name)
+		names = append(names, cmd.Aliases...)
+	}
+
+	return names
+}
+
+// folders returns the IDs of the folders the user can read, and the paths of the ones the user owns,
+// since paths are resolved from the root of the user.
+func (c *Completer) folders(username string) []string {
+	all, err := c.factory.Client.Folders(username, "", "")
+	if err != nil {
+		return nil
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	words := make([]string, 0, 2*len(all))
+	for _, folder := range all {
+		words = append(words, strconv.Itoa(folder.ID))
+	}
+
+	return append(words, c.ownFolderPaths(username)...)
+}
+
+// ownFolderPaths returns the paths of the folders the user owns, which new folders can be created under.
+func (c *Completer) ownFolderPaths(username string) []string {
+	all, err := c.factory.Client.Folders(username, "", "")
+	if err != nil {
+		return nil
+	}
+
+	var words []string
+	for _, folder := range all {
+		if !c.factory.Client.Owns(username, folder) {
+			continue
+		}
+
+		if path, err := c.factory.Client.FolderPath(folder.ID); err == nil {
+			words = append(words, path)
+		}
+	}
+	sort.Strings(words)
+
+	return words
+}
+
+// files returns the names of the files in the folder given by its ID or path.
+func (c *Completer) files(username string, ref string) []string {
+	all, err := c.factory.Client.Files(username, ref, "sort_name", "asc")
+	if err != nil {
+		return nil
+	}
+
+	words := make([]string, len(all))
+	for i, file := range all {
+		words[i] = file.Name
+	}
+
+	return words
+}
+
+func (c *Completer) trashItems(username string) []string {
+	items, err := c.factory.Client.Trash(username)
+	if err != nil {
+		return nil
+	}
+
+	words := make([]string, len(items))
+	for i, item := range items {
+		words[i] = strconv.Itoa(item.ID)
+	}
+
+	return words
+}
@@ -0,0 +1,119 @@
+package shell
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"virtual-file-system/internal/actions"
+	"virtual-file-system/vfs"
+)
+
+func TestCompleter_Complete(t *testing.T) {
+	client, _ := vfs.New()
+	defer client.Close()
+
+	client.Register("luke", "")
+	client.Register("mark", "")
+	client.Register("leia", "secret")
+	client.CreateFolder("luke", "Work", "")
+	client.CreateFolder("luke", "/Work/Reports", "")
+	client.CreateFolder("mark", "Shared", "")
+	client.Share("mark", "Shared", "luke", vfs.ReadOnly)
+	client.Upload("luke", "/Work", "todo.txt", "", strings.NewReader("todo"))
+	client.CreateFolder("leia", "Notes", "")
+
+	tests := []struct {
+		name     string
+		session  string
+		password string
+		head     string
+		want     []string
+	}{
+		{
+			name: "01. it should complete the command names and aliases.",
+			head: "get_",
+			want: commandNames(),
+		},
+		{
+			name: "02. it should complete the user names.",
+			head: "get_folders ",
+			want: []string{"leia", "luke", "mark"},
+		},
+		{
+			name: "03. it should complete the folder IDs the user can read and the paths of the folders the user owns.",
+			head: "rename_folder luke ",
+			want: []string{"1001", "1002", "1003", "/Work", "/Work/Reports"},
+		},
+		{
+			name: "04. it should complete the file names of the folder.",
+			head: "delete_file luke /Work t",
+			want: []string{"todo.txt"},
+		},
+		{
+			name:    "05. it should leave out the user name while a user is logged in.",
+			session: "luke",
+			head:    "get_files 1001 sort_name ",
+			want:    []string{"asc", "dsc"},
+		},
+		{
+			name: "06. it should complete the flags.",
+			head: "delete_folder luke /Work --",
+			want: []string{"--recursive"},
+		},
+		{
+			name: "07. it should complete the choices of the argument regardless of the alias.",
+			head: "ls luke /Work ",
+			want: []string{"sort_name", "sort_time", "sort_extension"},
+		},
+		{
+			name: "08. it should complete the values of the arguments given by their flag.",
+			head: "get_files --folder=/Work --user=luke --sort=",
+			want: []string{"--sort=sort_name", "--sort=sort_time", "--sort=sort_extension"},
+		},
+		{
+			name: "09. it should complete the flags of the arguments not given yet.",
+			head: "restore_version luke --file=todo.txt -",
+			want: []string{"--folder=", "--version="},
+		},
+		{
+			name: "10. it should complete the files of the folder given by its flag.",
+			head: "download_file --folder=1001 luke ",
+			want: []string{"todo.txt"},
+		},
+		{
+			name: "11. it should complete nothing past the last argument.",
+			head: "get_folders luke sort_name asc ",
+		},
+		{
+			name: "12. it should complete nothing after an unknown command.",
+			head: "unknown luke ",
+		},
+		{
+			name: "13. it should complete nothing of a user protected by a password unless logged in.",
+			head: "rename_folder leia ",
+		},
+		{
+			name:     "14. it should complete the arguments of a user protected by a password once logged in.",
+			session:  "leia",
+			password: "secret",
+			head:     "rename_folder ",
+			want:     []string{"1004", "/Notes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &actions.Factory{Client: client, Output: &actions.Output{W: &strings.Builder{}}}
+			if tt.session != "" {
+				login := []string{"login", tt.session}
+				if tt.password != "" {
+					login = append(login, tt.password)
+				}
+				f.CreateAction(login).Exec(login)
+			}
+
+			if got := NewCompleter(f).Complete(tt.head); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Completer.Complete() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Editor reads the lines typed in a terminal, with line editing, history and tab completion.
+// When the input is not a terminal, or the platform has no raw mode, it reads plain lines.
+//
+// The keys are
+//
+//	left, right, ctrl-b, ctrl-f        move the cursor
+//	home, end, ctrl-a, ctrl-e          move the cursor to the start or the end of the line
+//	up, down, ctrl-p, ctrl-n           recall the previous or the next line of the history
+//	backspace, delete                  delete the character before or under the cursor
+//	ctrl-u, ctrl-k, ctrl-w             delete up to the start of the line, up to its end, or the word before the cursor
+//	tab                                complete the word before the cursor, twice to list the candidates
+//	ctrl-c                             discard the line
+//	ctrl-d                             end the input on an empty line
+type Editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  int
+
+	// History holds the lines recalled with the up and down arrows, none if nil.
+	History *History
+
+	// Complete returns the words that may replace the last word of head, which is the line up to the cursor.
+	// The words not starting with the last word are left out by the editor. Nothing is completed if nil.
+	Complete func(head string) []string
+}
+
+// NewEditor returns an editor reading the keys from in, which is the terminal with file descriptor fd,
+// and printing the line being edited to out.
+func NewEditor(in *bufio.Reader, out io.Writer, fd int) *Editor {
+	return &Editor{in: in, out: out, fd: fd}
+}
+
+// ReadLine prints the prompt and returns the line typed, without the line break.
+// It returns io.EOF once the input ends, e.g. on ctrl-d.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+
+		line, err := e.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	defer restore()
+
+	return e.edit(prompt)
+}
+
+// Control keys.
+const (
+	keyCtrlA     = 'a' & 0x1f
+	keyCtrlB     = 'b' & 0x1f
+	keyCtrlC     = 'c' & 0x1f
+	keyCtrlD     = 'd' & 0x1f
+	keyCtrlE     = 'e' & 0x1f
+	keyCtrlF     = 'f' & 0x1f
+	keyCtrlH     = 'h' & 0x1f
+	keyCtrlK     = 'k' & 0x1f
+	keyCtrlN     = 'n' & 0x1f
+	keyCtrlP     = 'p' & 0x1f
+	keyCtrlU     = 'u' & 0x1f
+	keyCtrlW     = 'w' & 0x1f
+	keyTab       = '\t'
+	keyEnter     = '\r'
+	keyNewline   = '\n'
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// edit reads the keys of a terminal in raw mode until the line is entered.
+func (e *Editor) edit(prompt string) (string, error) {
+	l := &line{prompt: prompt, out: e.out}
+
+	var history []string
+	if e.History != nil {
+		history = e.History.Lines()
+	}
+
+	// recalled is the index of the history line shown, len(history) for the line being typed, which is kept in draft.
+	recalled := len(history)
+	var draft []rune
+
+	recall := func(index int) {
+		if index < 0 || index > len(history) || index == recalled {
+			return
+		}
+
+		if recalled == len(history) {
+			draft = l.buf
+		}
+
+		recalled = index
+		if index == len(history) {
+			l.set(draft)
+		} else {
+			l.set([]rune(history[index]))
+		}
+	}
+
+	tabs := 0
+	l.refresh()
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(l.buf) > 0 {
+				fmt.Fprintln(e.out)
+				return string(l.buf), nil
+			}
+			return "", err
+		}
+
+		if r == keyTab {
+			tabs++
+		} else {
+			tabs = 0
+		}
+
+		switch r {
+		case keyEnter, keyNewline:
+			fmt.Fprintln(e.out)
+			return string(l.buf), nil
+		case keyCtrlC:
+			fmt.Fprintln(e.out, "^C")
+			l.set(nil)
+			recalled = len(history)
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				fmt.Fprintln(e.out)
+				return "", io.EOF
+			}
+			l.delete()
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			l.move(-1)
+		case keyCtrlF:
+			l.move(1)
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.buf = append([]rune{}, l.buf[l.pos:]...)
+			l.pos = 0
+		case keyCtrlW:
+			l.deleteWord()
+		case keyCtrlP:
+			recall(recalled - 1)
+		case keyCtrlN:
+			recall(recalled + 1)
+		case keyBackspace, keyCtrlH:
+			l.backspace()
+		case keyTab:
+			e.complete(l, tabs)
+		case keyEscape:
+			switch e.escape() {
+			case "A":
+				recall(recalled - 1)
+			case "B":
+				recall(recalled + 1)
+			case "C":
+				l.move(1)
+			case "D":
+				l.move(-1)
+			case "H", "1~", "7~":
+				l.pos = 0
+			case "F", "4~", "8~":
+				l.pos = len(l.buf)
+			case "3~":
+				l.delete()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				l.insert([]rune{r})
+			}
+		}
+
+		l.refresh()
+	}
+}
+
+// escape reads the rest of an escape sequence, such as "\x1b[A" sent by the up arrow,
+// and returns its parameters and final character, e.g. "A", or "3~" for the delete key.
+func (e *Editor) escape() string {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return ""
+	}
+
+	var seq strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+
+		seq.WriteRune(r)
+		if r >= 0x40 && r <= 0x7e {
+			return seq.String()
+		}
+	}
+}
+
+// complete completes the word before the cursor with the candidates starting with it:
+// the only candidate followed by a space, or else the longest prefix they share.
+// The candidates are listed when there is nothing to add and tab is pressed twice.
+func (e *Editor) complete(l *line, tabs int) {
+	if e.Complete == nil {
+		return
+	}
+
+	head := string(l.buf[:l.pos])
+	word := head[strings.LastIndexAny(head, " \t")+1:]
+
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, c := range e.Complete(head) {
+		if strings.HasPrefix(c, word) && !seen[c] {
+			seen[c] = true
+			candidates = append(candidates, c)
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		fmt.Fprint(e.out, "\a")
+	case len(candidates) == 1:
+		// A flag waiting for its value, e.g. "--sort=", is not followed by a space.
+		completion := quote(candidates[0])
+		if !strings.HasSuffix(completion, "=") {
+			completion += " "
+		}
+		l.replace(len([]rune(word)), []rune(completion))
+	default:
+		prefix := commonPrefix(candidates)
+		if len(prefix) > len(word) && quote(prefix) == prefix {
+			l.replace(len([]rune(word)), []rune(prefix))
+			return
+		}
+
+		if tabs < 2 {
+			fmt.Fprint(e.out, "\a")
+			return
+		}
+
+		sort.Strings(candidates)
+		fmt.Fprintf(e.out, "\n%s\n", strings.Join(candidates, "  "))
+	}
+}
+
+// quote quotes the word for the shell if it holds spaces or other special characters.
+func quote(word string) string {
+	if !strings.ContainsAny(word, " \t'\"\\#$") {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// commonPrefix returns the longest prefix shared by the words.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+
+	return prefix
+}
+
+// line is the line being edited.
+type line struct {
+	prompt string
+	out    io.Writer
+
+	buf []rune
+	pos int
+}
+
+// refresh prints the prompt and the line over the current terminal line, then moves the cursor in place.
+// Lines wider than the terminal are not handled.
+func (l *line) refresh() {
+	fmt.Fprintf(l.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if n := len(l.buf) - l.pos; n > 0 {
+		fmt.Fprintf(l.out, "\x1b[%dD", n)
+	}
+}
+
+func (l *line) set(buf []rune) {
+	l.buf = append([]rune{}, buf...)
+	l.pos = len(l.buf)
+}
+
+func (l *line) move(n int) {
+	if pos := l.pos + n; pos >= 0 && pos <= len(l.buf) {
+		l.pos = pos
+	}
+}
+
+func (l *line) insert(runes []rune) {
+	buf := make([]rune, 0, len(l.buf)+len(runes))
+	buf = append(buf, l.buf[:l.pos]...)
+	buf = append(buf, runes...)
+	l.buf = append(buf, l.buf[l.pos:]...)
+	l.pos += len(runes)
+}
+
+// replace replaces the n characters before the cursor with the runes.
+func (l *line) replace(n int, runes []rune) {
+	l.buf = append(l.buf[:l.pos-n], l.buf[l.pos:]...)
+	l.pos -= n
+	l.insert(runes)
+}
+
+func (l *line) backspace() {
+	if l.pos > 0 {
+		l.buf = append(l.buf[:l.pos-1], l.buf[l.pos:]...)
+		l.pos--
+	}
+}
+
+func (l *line) delete() {
+	if l.pos < len(l.buf) {
+		l.buf = append(l.buf[:l.pos], l.buf[l.pos+1:]...)
+	}
+}
+
+// deleteWord deletes the word before the cursor, together with the spaces after it.
+func (l *line) deleteWord() {
+	start := l.pos
+	for start > 0 && unicode.IsSpace(l.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(l.buf[start-1]) {
+		start--
+	}
+
+	l.buf = append(l.buf[:start], l.buf[l.pos:]...)
+	l.pos = start
+}
@@ -0,0 +1,109 @@
+package shell
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestEditor_edit(t *testing.T) {
+	complete := func(head string) []string {
+		return []string{"get_files", "get_folders", "register", "My Work"}
+	}
+
+	tests := []struct {
+		name    string
+		keys    string
+		history []string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "01. it should insert the characters at the cursor.",
+			keys: "get_foders\x1b[D\x1b[D\x1b[D\x1b[Dl\r",
+			want: "get_folders",
+		},
+		{
+			name: "02. it should delete before and under the cursor.",
+			keys: "registerr\x7f\x01\x1b[3~r\x1b[F x\x7f\r",
+			want: "register ",
+		},
+		{
+			name: "03. it should delete the word before the cursor and up to the start of the line.",
+			keys: "register luke\x17mark\x01\x1b[C\x1b[C\x0b\x05\x15x\r",
+			want: "x",
+		},
+		{
+			name:    "04. it should recall the history with the arrows and keep the line being typed.",
+			keys:    "list\x1b[A\x1b[A\x1b[A\x1b[B\x1b[B\x1b[B\r",
+			history: []string{"register luke", "get_folders luke"},
+			want:    "list",
+		},
+		{
+			name:    "05. it should enter a recalled line.",
+			keys:    "\x1b[A\x1b[A\x1b[B\r",
+			history: []string{"register luke", "get_folders luke"},
+			want:    "get_folders luke",
+		},
+		{
+			name: "06. it should complete the only candidate followed by a space.",
+			keys: "reg\tluke\r",
+			want: "register luke",
+		},
+		{
+			name: "07. it should complete the prefix shared by the candidates.",
+			keys: "g\ti\t\r",
+			want: "get_files ",
+		},
+		{
+			name: "08. it should quote the completed words holding spaces.",
+			keys: "get_files luke M\t\r",
+			want: "get_files luke 'My Work' ",
+		},
+		{
+			name: "09. it should discard the line on ctrl-c.",
+			keys: "register\x03get_folders\r",
+			want: "get_folders",
+		},
+		{
+			name:    "10. it should return EOF on ctrl-d in an empty line.",
+			keys:    "\x04",
+			wantErr: io.EOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEditor(bufio.NewReader(strings.NewReader(tt.keys)), ioutil.Discard, -1)
+			e.History = &History{lines: tt.history, size: DefaultHistorySize}
+			e.Complete = complete
+
+			got, err := e.edit("# ")
+			if err != tt.wantErr {
+				t.Errorf("Editor.edit() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Editor.edit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditor_ReadLine(t *testing.T) {
+	var out strings.Builder
+	e := NewEditor(bufio.NewReader(strings.NewReader("register luke\r\nget_folders luke")), &out, -1)
+
+	for _, want := range []string{"register luke", "get_folders luke"} {
+		if got, err := e.ReadLine("# "); err != nil || got != want {
+			t.Errorf("Editor.ReadLine() = %q, %v, want %q", got, err, want)
+		}
+	}
+	if _, err := e.ReadLine("# "); err != io.EOF {
+		t.Errorf("Editor.ReadLine() error = %v, want EOF", err)
+	}
+	if got := out.String(); got != "# # # " {
+		t.Errorf("Editor.ReadLine() printed %q, want the prompts only", got)
+	}
+}
@@ -0,0 +1,107 @@
+package shell
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// DefaultHistorySize is the number of lines kept in the history.
+const DefaultHistorySize = 1000
+
+// History is the list of the lines typed in the shell, kept in a file across sessions.
+type History struct {
+	path  string
+	size  int
+	lines []string
+}
+
+// LoadHistory reads the history kept in the file at path, which is created by the first Add if it does not exist.
+// Only the latest size lines are kept. The history is kept in memory only if path is empty.
+func LoadHistory(path string, size int) (*History, error) {
+	h := &History{path: path, size: size}
+	if path == "" {
+		return h, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.lines = append(h.lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The file only grows while the shell runs, so it is trimmed when loaded.
+	if len(h.lines) > size {
+		h.lines = h.lines[len(h.lines)-size:]
+
+		data := strings.Join(h.lines, "\n") + "\n"
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+// Lines returns the lines of the history, the oldest first.
+func (h *History) Lines() []string {
+	return h.lines
+}
+
+// Add appends the line to the history. Blank lines, repeats of the previous line
+// and lines holding a password, i.e. "register" or "login" with a password, are left out.
+func (h *History) Add(line string) error {
+	if strings.TrimSpace(line) == "" || hasPassword(line) {
+		return nil
+	}
+
+	if len(h.lines) > 0 && h.lines[len(h.lines)-1] == line {
+		return nil
+	}
+
+	h.lines = append(h.lines, line)
+	if len(h.lines) > h.size {
+		h.lines = h.lines[len(h.lines)-h.size:]
+	}
+
+	if h.path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// hasPassword reports whether the line registers or logs in a user with a password.
+func hasPassword(line string) bool {
+	args, err := shlex.Split(line)
+	if err != nil {
+		// The line cannot be told apart, so it is left out to be safe.
+		return true
+	}
+
+	return len(args) > 2 && (args[0] == "register" || args[0] == "login")
+}
@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history")
+
+	h, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+
+	for _, line := range []string{
+		"register luke",
+		"register mark secret",
+		"login mark 'my secret'",
+		"",
+		"get_folders luke",
+		"get_folders luke",
+		"create_folder luke Work",
+		"get_files luke /Work",
+	} {
+		if err := h.Add(line); err != nil {
+			t.Fatalf("History.Add() error = %v", err)
+		}
+	}
+
+	want := []string{"get_folders luke", "create_folder luke Work", "get_files luke /Work"}
+	if got := h.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("History.Lines() = %q, want %q", got, want)
+	}
+
+	loaded, err := LoadHistory(path, 2)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if got := loaded.Lines(); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("LoadHistory() lines = %q, want %q", got, want[1:])
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if got := string(data); got != "create_folder luke Work\nget_files luke /Work\n" {
+		t.Errorf("LoadHistory() should trim the file, got %q", got)
+	}
+}
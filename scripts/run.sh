@@ -10,9 +10,9 @@ PROJECT="$(basename $PROJECT_ROOT)"
 # Detremine the output directory
 OUT_DIR="$PROJECT_ROOT/bin"
 
-# Run the project
+# Run the project, passing the arguments along, e.g. "run script.vfs"
 cd $OUT_DIR
-./$PROJECT.exe
+./$PROJECT.exe "$@"
 
 EXIT_STATUS=$?
 
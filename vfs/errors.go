@@ -0,0 +1,152 @@
+package vfs
+
+import (
+	"errors"
+	"virtual-file-system/internal/services"
+)
+
+// The sentinel errors the errors returned by a Client match with errors.Is, e.g.
+//
+//	if errors.Is(err, vfs.ErrNotFound) {
+//		...
+//	}
+var (
+	// ErrNotFound means a user, folder, file or deleted item does not exist.
+	ErrNotFound = services.ErrNotFound
+
+	// ErrAlreadyExists means the name is already taken.
+	ErrAlreadyExists = services.ErrAlreadyExists
+
+	// ErrPermissionDenied means the user is not allowed to perform the operation.
+	ErrPermissionDenied = services.ErrPermissionDenied
+
+	// ErrUnauthenticated means the credentials are wrong.
+	ErrUnauthenticated = services.ErrUnauthenticated
+
+	// ErrConflict means the operation does not fit the current state, such as deleting a non-empty folder.
+	ErrConflict = services.ErrConflict
+
+	// ErrInvalid means an argument is malformed, such as an empty name.
+	ErrInvalid = services.ErrInvalid
+)
+
+// Kind is the category of an error, telling the caller how it may react to it.
+type Kind int
+
+const (
+	// Other is an error that falls in no other category, such as an I/O failure.
+	Other Kind = iota
+
+	// NotFound is the kind of the errors matching ErrNotFound.
+	NotFound
+
+	// AlreadyExists is the kind of the errors matching ErrAlreadyExists.
+	AlreadyExists
+
+	// PermissionDenied is the kind of the errors matching ErrPermissionDenied.
+	PermissionDenied
+
+	// Unauthenticated is the kind of the errors matching ErrUnauthenticated.
+	Unauthenticated
+
+	// Conflict is the kind of the errors matching ErrConflict.
+	Conflict
+
+	// Invalid is the kind of the errors matching ErrInvalid.
+	Invalid
+)
+
+var kindNames = map[Kind]string{
+	Other:            "other",
+	NotFound:         "not found",
+	AlreadyExists:    "already exists",
+	PermissionDenied: "permission denied",
+	Unauthenticated:  "unauthenticated",
+	Conflict:         "conflict",
+	Invalid:          "invalid",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// kindErrors are the sentinel errors of the kinds.
+var kindErrors = []struct {
+	kind Kind
+	err  error
+}{
+	{NotFound, ErrNotFound},
+	{AlreadyExists, ErrAlreadyExists},
+	{PermissionDenied, ErrPermissionDenied},
+	{Unauthenticated, ErrUnauthenticated},
+	{Conflict, ErrConflict},
+	{Invalid, ErrInvalid},
+}
+
+// Error is the error returned by the operations of a Client.
+// It matches the sentinel error of its kind with errors.Is.
+type Error struct {
+	// Op is the operation that failed, e.g. "create_folder".
+	Op string
+
+	// Kind is the category of the error.
+	Kind Kind
+
+	// Entity is the kind of entity the error is about, such as "user", "folder" or "file", empty if unknown.
+	Entity string
+
+	// ID identifies the entity, such as the user name, the folder ID or the file name.
+	ID string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the message of the underlying error, so it reads the same as on the command line.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of err, or Other if it is not an *Error.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return Other
+}
+
+// wrap returns err as an *Error of the operation, or nil if err is nil.
+func wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	e = &Error{Op: op, Kind: Other, Err: err}
+	for _, k := range kindErrors {
+		if errors.Is(err, k.err) {
+			e.Kind = k.kind
+			break
+		}
+	}
+
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		e.Entity = serviceErr.Entity
+		e.ID = serviceErr.ID
+	}
+
+	return e
+}
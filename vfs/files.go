@@ -0,0 +1,149 @@
+package vfs
+
+import (
+	"context"
+	"io"
+	"virtual-file-system/internal/models"
+)
+
+// File is a file of the file system.
+type File = models.File
+
+// FileVersion is a revision of the content of a file.
+type FileVersion = models.FileVersion
+
+// Upload creates a file under the folder with the content, which may be nil for an empty file.
+func (c *Client) Upload(username string, ref string, name string, desc string, content io.Reader) (*File, error) {
+	return c.UploadContext(context.Background(), username, ref, name, desc, content)
+}
+
+// UploadContext is Upload with a context.
+func (c *Client) UploadContext(ctx context.Context, username string, ref string, name string, desc string, content io.Reader) (*File, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("upload_file", err)
+	}
+
+	file, err := c.factory.GetFileService().UploadContext(ctx, username, folder.ID, name, desc, content)
+	return file, wrap("upload_file", err)
+}
+
+// UploadVersion replaces the content of the file with a new revision, creating the file if needed.
+func (c *Client) UploadVersion(username string, ref string, name string, desc string, content io.Reader) (*File, error) {
+	return c.UploadVersionContext(context.Background(), username, ref, name, desc, content)
+}
+
+// UploadVersionContext is UploadVersion with a context.
+func (c *Client) UploadVersionContext(ctx context.Context, username string, ref string, name string, desc string, content io.Reader) (*File, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("upload_file", err)
+	}
+
+	file, err := c.factory.GetFileService().UploadVersionContext(ctx, username, folder.ID, name, desc, content)
+	return file, wrap("upload_file", err)
+}
+
+// Open returns the content of the file, which should be closed once read.
+func (c *Client) Open(username string, ref string, name string) (io.ReadCloser, error) {
+	return c.OpenContext(context.Background(), username, ref, name)
+}
+
+// OpenContext is Open with a context.
+func (c *Client) OpenContext(ctx context.Context, username string, ref string, name string) (io.ReadCloser, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("download_file", err)
+	}
+
+	content, err := c.factory.GetFileService().OpenContext(ctx, username, folder.ID, name)
+	return content, wrap("download_file", err)
+}
+
+// Files returns the files under the folder, sorted by "sort_name", "sort_time" or "sort_extension" in "asc" or "dsc" order.
+func (c *Client) Files(username string, ref string, sortBy string, order string) ([]File, error) {
+	return c.FilesContext(context.Background(), username, ref, sortBy, order)
+}
+
+// FilesContext is Files with a context.
+func (c *Client) FilesContext(ctx context.Context, username string, ref string, sortBy string, order string) ([]File, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("get_files", err)
+	}
+
+	files, err := c.factory.GetFileService().GetAllContext(ctx, username, folder.ID, sortBy, order)
+	return files, wrap("get_files", err)
+}
+
+// MoveFile moves the file under the destination folder, renaming it to destName unless it is empty.
+func (c *Client) MoveFile(username string, ref string, name string, destRef string, destName string) (*File, error) {
+	return c.MoveFileContext(context.Background(), username, ref, name, destRef, destName)
+}
+
+// MoveFileContext is MoveFile with a context.
+func (c *Client) MoveFileContext(ctx context.Context, username string, ref string, name string, destRef string, destName string) (*File, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("move_file", err)
+	}
+
+	dest, err := c.resolve(ctx, username, destRef)
+	if err != nil {
+		return nil, wrap("move_file", err)
+	}
+
+	if destName == "" {
+		destName = name
+	}
+
+	file, err := c.factory.GetFileService().MoveContext(ctx, username, folder.ID, name, dest.ID, destName)
+	return file, wrap("move_file", err)
+}
+
+// DeleteFile moves the file to the trash of the user.
+func (c *Client) DeleteFile(username string, ref string, name string) error {
+	return c.DeleteFileContext(context.Background(), username, ref, name)
+}
+
+// DeleteFileContext is DeleteFile with a context.
+func (c *Client) DeleteFileContext(ctx context.Context, username string, ref string, name string) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("delete_file", err)
+	}
+
+	return wrap("delete_file", c.factory.GetFileService().DeleteContext(ctx, username, folder.ID, name))
+}
+
+// Versions returns the retained revisions of the file, oldest first.
+func (c *Client) Versions(username string, ref string, name string) ([]FileVersion, error) {
+	return c.VersionsContext(context.Background(), username, ref, name)
+}
+
+// VersionsContext is Versions with a context.
+func (c *Client) VersionsContext(ctx context.Context, username string, ref string, name string) ([]FileVersion, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("list_versions", err)
+	}
+
+	versions, err := c.factory.GetFileService().ListVersionsContext(ctx, username, folder.ID, name)
+	return versions, wrap("list_versions", err)
+}
+
+// RestoreVersion makes the content of an older revision the current one, as a new revision.
+func (c *Client) RestoreVersion(username string, ref string, name string, number int) (*File, error) {
+	return c.RestoreVersionContext(context.Background(), username, ref, name, number)
+}
+
+// RestoreVersionContext is RestoreVersion with a context.
+func (c *Client) RestoreVersionContext(ctx context.Context, username string, ref string, name string, number int) (*File, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("restore_version", err)
+	}
+
+	file, err := c.factory.GetFileService().RestoreVersionContext(ctx, username, folder.ID, name, number)
+	return file, wrap("restore_version", err)
+}
@@ -0,0 +1,190 @@
+package vfs
+
+import (
+	"context"
+	"virtual-file-system/internal/models"
+	"virtual-file-system/internal/services"
+)
+
+// Folder is a folder of the file system.
+type Folder = models.Folder
+
+// Grant is the access to a folder given to a user or a group.
+type Grant = models.Grant
+
+const (
+	// ReadOnly lets the grantee list and download.
+	ReadOnly = models.AccessReadOnly
+
+	// ReadWrite lets the grantee change the content as well.
+	ReadWrite = models.AccessReadWrite
+)
+
+// CreateFolder creates a folder given by its name, at the root of the user, or by its path under an existing parent, e.g. "/work/reports".
+func (c *Client) CreateFolder(username string, path string, desc string) (*Folder, error) {
+	return c.CreateFolderContext(context.Background(), username, path, desc)
+}
+
+// CreateFolderContext is CreateFolder with a context.
+func (c *Client) CreateFolderContext(ctx context.Context, username string, path string, desc string) (*Folder, error) {
+	folder, err := c.factory.GetFolderService().CreateContext(ctx, path, username, desc)
+	return folder, wrap("create_folder", err)
+}
+
+// Folder returns the folder referred to by its ID or its path.
+func (c *Client) Folder(username string, ref string) (*Folder, error) {
+	return c.FolderContext(context.Background(), username, ref)
+}
+
+// FolderContext is Folder with a context.
+func (c *Client) FolderContext(ctx context.Context, username string, ref string) (*Folder, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	return folder, wrap("get_folder", err)
+}
+
+// Folders returns the folders the user can read, sorted by "sort_name" or "sort_time" in "asc" or "dsc" order.
+func (c *Client) Folders(username string, sortBy string, order string) ([]Folder, error) {
+	return c.FoldersContext(context.Background(), username, sortBy, order)
+}
+
+// FoldersContext is Folders with a context.
+func (c *Client) FoldersContext(ctx context.Context, username string, sortBy string, order string) ([]Folder, error) {
+	folders, err := c.factory.GetFolderService().GetAllContext(ctx, username, sortBy, order)
+	return folders, wrap("get_folders", err)
+}
+
+// Owns reports whether the user owns the folder, by creating it or one of the folders above it.
+// The other folders the user can read are shared with them.
+func (c *Client) Owns(username string, folder Folder) bool {
+	return c.factory.GetAuthorizer().FolderPermission(username, folder) == services.PermissionOwner
+}
+
+// OwnsContext is Owns with a context.
+func (c *Client) OwnsContext(ctx context.Context, username string, folder Folder) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, wrap("get_folder", err)
+	}
+
+	return c.Owns(username, folder), nil
+}
+
+// FolderPath returns the absolute path of the folder with given ID.
+func (c *Client) FolderPath(id int) (string, error) {
+	return c.FolderPathContext(context.Background(), id)
+}
+
+// FolderPathContext is FolderPath with a context.
+func (c *Client) FolderPathContext(ctx context.Context, id int) (string, error) {
+	path, err := c.factory.GetFolderService().PathContext(ctx, id)
+	return path, wrap("get_folder", err)
+}
+
+// RenameFolder renames the folder.
+func (c *Client) RenameFolder(username string, ref string, name string) error {
+	return c.RenameFolderContext(context.Background(), username, ref, name)
+}
+
+// RenameFolderContext is RenameFolder with a context.
+func (c *Client) RenameFolderContext(ctx context.Context, username string, ref string, name string) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("rename_folder", err)
+	}
+
+	return wrap("rename_folder", c.factory.GetFolderService().RenameContext(ctx, folder.ID, name, username))
+}
+
+// MoveFolder moves the folder under the parent folder, or to the root if parent is "/", renaming it to name unless it is empty.
+func (c *Client) MoveFolder(username string, ref string, parent string, name string) error {
+	return c.MoveFolderContext(context.Background(), username, ref, parent, name)
+}
+
+// MoveFolderContext is MoveFolder with a context.
+func (c *Client) MoveFolderContext(ctx context.Context, username string, ref string, parent string, name string) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("move_folder", err)
+	}
+
+	var parentID int
+	if parent != "/" {
+		p, err := c.resolve(ctx, username, parent)
+		if err != nil {
+			return wrap("move_folder", err)
+		}
+		parentID = p.ID
+	}
+
+	if name == "" {
+		name = folder.Name
+	}
+
+	return wrap("move_folder", c.factory.GetFolderService().MoveContext(ctx, folder.ID, parentID, name, username))
+}
+
+// DeleteFolder moves the folder to the trash of the user.
+// A folder with subfolders or files is only deleted if recursive is true.
+func (c *Client) DeleteFolder(username string, ref string, recursive bool) error {
+	return c.DeleteFolderContext(context.Background(), username, ref, recursive)
+}
+
+// DeleteFolderContext is DeleteFolder with a context.
+func (c *Client) DeleteFolderContext(ctx context.Context, username string, ref string, recursive bool) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("delete_folder", err)
+	}
+
+	return wrap("delete_folder", c.factory.GetFolderService().DeleteContext(ctx, folder.ID, username, recursive))
+}
+
+// Share gives the grantee, a user or a "@group", ReadOnly or ReadWrite access to the folder and everything below it.
+func (c *Client) Share(username string, ref string, grantee string, access string) error {
+	return c.ShareContext(context.Background(), username, ref, grantee, access)
+}
+
+// ShareContext is Share with a context.
+func (c *Client) ShareContext(ctx context.Context, username string, ref string, grantee string, access string) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("share_folder", err)
+	}
+
+	return wrap("share_folder", c.factory.GetShareService().Share(folder.ID, grantee, access, username))
+}
+
+// Unshare takes the access to the folder back from the grantee.
+func (c *Client) Unshare(username string, ref string, grantee string) error {
+	return c.UnshareContext(context.Background(), username, ref, grantee)
+}
+
+// UnshareContext is Unshare with a context.
+func (c *Client) UnshareContext(ctx context.Context, username string, ref string, grantee string) error {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return wrap("unshare_folder", err)
+	}
+
+	return wrap("unshare_folder", c.factory.GetShareService().Unshare(folder.ID, grantee, username))
+}
+
+// Grants returns the grants on the folder.
+func (c *Client) Grants(username string, ref string) ([]Grant, error) {
+	return c.GrantsContext(context.Background(), username, ref)
+}
+
+// GrantsContext is Grants with a context.
+func (c *Client) GrantsContext(ctx context.Context, username string, ref string) ([]Grant, error) {
+	folder, err := c.resolve(ctx, username, ref)
+	if err != nil {
+		return nil, wrap("get_grants", err)
+	}
+
+	grants, err := c.factory.GetShareService().GetAll(folder.ID, username)
+	return grants, wrap("get_grants", err)
+}
+
+// resolve returns the folder referred to by its ID or its path.
+func (c *Client) resolve(ctx context.Context, username string, ref string) (*Folder, error) {
+	return c.factory.GetFolderService().ResolveContext(ctx, username, ref)
+}
@@ -0,0 +1,42 @@
+package vfs
+
+import "time"
+
+// Option tunes the file system created by New.
+type Option func(*options)
+
+type options struct {
+	dataDir            string
+	checkpointInterval int
+	maxVersions        int
+	trashRetention     time.Duration
+}
+
+// WithDataDir persists the file system under the directory instead of keeping it in memory.
+// The directory is created if needed, and the file system found there is loaded.
+func WithDataDir(dir string) Option {
+	return func(o *options) {
+		o.dataDir = dir
+	}
+}
+
+// WithCheckpointInterval sets the number of logged operations between two snapshots of the data directory.
+func WithCheckpointInterval(n int) Option {
+	return func(o *options) {
+		o.checkpointInterval = n
+	}
+}
+
+// WithMaxVersions sets the number of revisions retained per file, zero or less keeps every revision.
+func WithMaxVersions(n int) Option {
+	return func(o *options) {
+		o.maxVersions = n
+	}
+}
+
+// WithTrashRetention sets how long deleted items are kept in the trash, zero or less keeps them until emptied.
+func WithTrashRetention(retention time.Duration) Option {
+	return func(o *options) {
+		o.trashRetention = retention
+	}
+}
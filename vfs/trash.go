@@ -0,0 +1,51 @@
+package vfs
+
+import (
+	"context"
+	"virtual-file-system/internal/models"
+)
+
+// TrashItem is a deleted folder or file.
+type TrashItem = models.TrashItem
+
+// Trash returns the items deleted by the user, still restorable.
+func (c *Client) Trash(username string) ([]TrashItem, error) {
+	return c.TrashContext(context.Background(), username)
+}
+
+// TrashContext is Trash with a context.
+func (c *Client) TrashContext(ctx context.Context, username string) ([]TrashItem, error) {
+	items, err := c.factory.GetTrashService().ListContext(ctx, username)
+	return items, wrap("list_trash", err)
+}
+
+// Restore puts the deleted item back where it was.
+func (c *Client) Restore(username string, id int) error {
+	return c.RestoreContext(context.Background(), username, id)
+}
+
+// RestoreContext is Restore with a context.
+func (c *Client) RestoreContext(ctx context.Context, username string, id int) error {
+	return wrap("restore", c.factory.GetTrashService().RestoreContext(ctx, id, username))
+}
+
+// EmptyTrash permanently deletes the items deleted by the user.
+func (c *Client) EmptyTrash(username string) error {
+	return c.EmptyTrashContext(context.Background(), username)
+}
+
+// EmptyTrashContext is EmptyTrash with a context.
+func (c *Client) EmptyTrashContext(ctx context.Context, username string) error {
+	return wrap("empty_trash", c.factory.GetTrashService().EmptyContext(ctx, username))
+}
+
+// PurgeTrash permanently deletes the items kept longer than the retention period, see WithTrashRetention.
+// Listing and restoring items purge them on the way, long running processes call it from time to time as well.
+func (c *Client) PurgeTrash() error {
+	return c.PurgeTrashContext(context.Background())
+}
+
+// PurgeTrashContext is PurgeTrash with a context.
+func (c *Client) PurgeTrashContext(ctx context.Context) error {
+	return wrap("purge_trash", c.factory.GetTrashService().PurgeExpiredContext(ctx))
+}
@@ -0,0 +1,115 @@
+package vfs
+
+import (
+	"context"
+	"virtual-file-system/internal/models"
+)
+
+// Group is a named set of users that folders can be shared with at once.
+type Group = models.Group
+
+// Register adds a user, who has to authenticate with the password unless it is empty.
+func (c *Client) Register(username string, password string) error {
+	return c.RegisterContext(context.Background(), username, password)
+}
+
+// RegisterContext is Register with a context.
+func (c *Client) RegisterContext(ctx context.Context, username string, password string) error {
+	return wrap("register", c.factory.GetUserService().RegisterContext(ctx, username, password))
+}
+
+// Authenticate checks the password of the user.
+func (c *Client) Authenticate(username string, password string) error {
+	return c.AuthenticateContext(context.Background(), username, password)
+}
+
+// AuthenticateContext is Authenticate with a context.
+func (c *Client) AuthenticateContext(ctx context.Context, username string, password string) error {
+	return wrap("login", c.factory.GetUserService().AuthenticateContext(ctx, username, password))
+}
+
+// ChangePassword replaces the password of the user, an empty one lets the user act without authenticating.
+func (c *Client) ChangePassword(username string, password string) error {
+	return c.ChangePasswordContext(context.Background(), username, password)
+}
+
+// ChangePasswordContext is ChangePassword with a context.
+func (c *Client) ChangePasswordContext(ctx context.Context, username string, password string) error {
+	return wrap("change_password", c.factory.GetUserService().ChangePasswordContext(ctx, username, password, username))
+}
+
+// DeleteUser deletes the user, who should have deleted their folders beforehand.
+// The folders shared with the user and the groups they created go away with them, the items in their trash are purged.
+func (c *Client) DeleteUser(username string) error {
+	return c.DeleteUserContext(context.Background(), username)
+}
+
+// DeleteUserContext is DeleteUser with a context.
+func (c *Client) DeleteUserContext(ctx context.Context, username string) error {
+	return wrap("delete_user", c.factory.GetUserService().DeleteContext(ctx, username, username))
+}
+
+// HasPassword reports whether the user has to authenticate before acting.
+func (c *Client) HasPassword(username string) bool {
+	return c.factory.GetUserService().HasPassword(username)
+}
+
+// HasPasswordContext is HasPassword with a context.
+func (c *Client) HasPasswordContext(ctx context.Context, username string) (bool, error) {
+	has, err := c.factory.GetUserService().HasPasswordContext(ctx, username)
+	return has, wrap("login", err)
+}
+
+// Users returns the names of the registered users in alphabetical order.
+func (c *Client) Users() []string {
+	return c.factory.GetUserService().Names()
+}
+
+// UsersContext is Users with a context.
+func (c *Client) UsersContext(ctx context.Context) ([]string, error) {
+	names, err := c.factory.GetUserService().NamesContext(ctx)
+	return names, wrap("get_users", err)
+}
+
+// CreateGroup creates a group, which only its creator can change the members of.
+func (c *Client) CreateGroup(username string, name string) (*Group, error) {
+	return c.CreateGroupContext(context.Background(), username, name)
+}
+
+// CreateGroupContext is CreateGroup with a context.
+func (c *Client) CreateGroupContext(ctx context.Context, username string, name string) (*Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrap("create_group", err)
+	}
+
+	group, err := c.factory.GetGroupService().Create(name, username)
+	return group, wrap("create_group", err)
+}
+
+// AddToGroup adds the member to the group.
+func (c *Client) AddToGroup(username string, name string, member string) error {
+	return c.AddToGroupContext(context.Background(), username, name, member)
+}
+
+// AddToGroupContext is AddToGroup with a context.
+func (c *Client) AddToGroupContext(ctx context.Context, username string, name string, member string) error {
+	if err := ctx.Err(); err != nil {
+		return wrap("add_to_group", err)
+	}
+
+	return wrap("add_to_group", c.factory.GetGroupService().AddMember(name, member, username))
+}
+
+// RemoveFromGroup removes the member from the group.
+func (c *Client) RemoveFromGroup(username string, name string, member string) error {
+	return c.RemoveFromGroupContext(context.Background(), username, name, member)
+}
+
+// RemoveFromGroupContext is RemoveFromGroup with a context.
+func (c *Client) RemoveFromGroupContext(ctx context.Context, username string, name string, member string) error {
+	if err := ctx.Err(); err != nil {
+		return wrap("remove_from_group", err)
+	}
+
+	return wrap("remove_from_group", c.factory.GetGroupService().RemoveMember(name, member, username))
+}
@@ -0,0 +1,91 @@
+// Package vfs is the Go API of the virtual file system, for programs that embed it rather than
+// driving the command line.
+//
+// A Client is created with New and its behavior tuned with options:
+//
+//	client, err := vfs.New(vfs.WithDataDir("/var/lib/vfs"), vfs.WithMaxVersions(5))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer client.Close()
+//
+//	if err := client.Register("alice", "secret"); err != nil {
+//		log.Fatal(err)
+//	}
+//	folder, err := client.CreateFolder("alice", "work", "quarterly reports")
+//
+// Every operation is made on behalf of the user given as first argument, folders are referred to
+// by either their ID or their path, e.g. "1001", "/work/reports" or "1001/reports", and the errors
+// returned are *Error values telling what went wrong.
+//
+// Every operation has a variant ending in Context, e.g. CreateFolderContext, which gives up once the
+// context is done, returning an *Error wrapping its error.
+package vfs
+
+import (
+	"io/fs"
+	"net/http"
+	"virtual-file-system/internal/iofs"
+	"virtual-file-system/internal/server"
+	"virtual-file-system/internal/services"
+)
+
+// Client gives access to the users, folders and files of a virtual file system.
+// It is safe for concurrent use by multiple goroutines.
+type Client struct {
+	factory *services.Factory
+	store   *services.DiskStore
+}
+
+// New returns a client of a new virtual file system, kept in memory unless WithDataDir is given.
+// The deleted items whose retention elapsed are purged from the trash on the way.
+func New(opts ...Option) (*Client, error) {
+	o := options{
+		checkpointInterval: services.DefaultCheckpointInterval,
+		maxVersions:        services.DefaultMaxVersions,
+		trashRetention:     services.DefaultTrashRetention,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	client := &Client{factory: services.NewFactory()}
+	client.factory.SetMaxVersions(o.maxVersions)
+	client.factory.SetTrashRetention(o.trashRetention)
+
+	if o.dataDir != "" {
+		store, err := services.OpenDiskStore(o.dataDir, o.checkpointInterval)
+		if err != nil {
+			return nil, wrap("open", err)
+		}
+		client.store = store
+		client.factory.SetStore(store)
+	}
+
+	if err := client.factory.GetTrashService().PurgeExpired(); err != nil {
+		client.Close()
+		return nil, wrap("open", err)
+	}
+
+	return client, nil
+}
+
+// Close flushes the file system to its data directory, if any.
+// The client should not be used afterwards.
+func (c *Client) Close() error {
+	if c.store == nil {
+		return nil
+	}
+
+	return wrap("close", c.store.Close())
+}
+
+// Handler returns the HTTP handler serving the file system as a JSON REST API, and over WebDAV under /dav.
+func (c *Client) Handler() http.Handler {
+	return server.New(c.factory)
+}
+
+// FS returns a read-only io/fs view of the folders and files the user can read.
+func (c *Client) FS(username string) fs.FS {
+	return iofs.New(c.factory, username)
+}
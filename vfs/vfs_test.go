@@ -0,0 +1,197 @@
+package vfs
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNew_WithDataDir(t *testing.T) {
+	dir := t.TempDir()
+
+	client, err := New(WithDataDir(dir), WithMaxVersions(2))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	client.Register("luke", "")
+	client.CreateFolder("luke", "Work", "")
+	client.CreateFolder("luke", "/Work/Reports", "")
+	for _, content := range []string{"v1", "v2", "v3"} {
+		if _, err := client.UploadVersion("luke", "/Work/Reports", "q3.txt", "", strings.NewReader(content)); err != nil {
+			t.Fatalf("Client.UploadVersion() error = %v", err)
+		}
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Client.Close() error = %v", err)
+	}
+
+	client, err = New(WithDataDir(dir))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer client.Close()
+
+	content, err := client.Open("luke", "/Work/Reports", "q3.txt")
+	if err != nil {
+		t.Fatalf("Client.Open() error = %v", err)
+	}
+	defer content.Close()
+
+	got, _ := ioutil.ReadAll(content)
+	if string(got) != "v3" {
+		t.Errorf("Client.Open() = %q, want %q", got, "v3")
+	}
+
+	versions, _ := client.Versions("luke", "/Work/Reports", "q3.txt")
+	if len(versions) != 2 {
+		t.Errorf("Client.Versions() = %d versions, want 2", len(versions))
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	client, _ := New()
+	client.Register("luke", "secret")
+	client.Register("mark", "")
+	client.CreateFolder("luke", "Work", "")
+	client.Upload("luke", "/Work", "1.tc", "", nil)
+
+	tests := []struct {
+		name   string
+		op     func() error
+		wantOp string
+		want   Kind
+		is     error
+	}{
+		{
+			name:   "01. it should return NotFound for unknown folders.",
+			op:     func() error { return client.RenameFolder("luke", "/Missing", "Other") },
+			wantOp: "rename_folder",
+			want:   NotFound,
+			is:     ErrNotFound,
+		},
+		{
+			name: "02. it should return AlreadyExists for taken names.",
+			op: func() error {
+				_, err := client.Upload("luke", "/Work", "1.tc", "", nil)
+				return err
+			},
+			wantOp: "upload_file",
+			want:   AlreadyExists,
+			is:     ErrAlreadyExists,
+		},
+		{
+			name:   "03. it should return PermissionDenied for the folders of other users.",
+			op:     func() error { return client.DeleteFolder("mark", "1001", true) },
+			wantOp: "delete_folder",
+			want:   PermissionDenied,
+			is:     ErrPermissionDenied,
+		},
+		{
+			name:   "04. it should return Unauthenticated for wrong passwords.",
+			op:     func() error { return client.Authenticate("luke", "wrong") },
+			wantOp: "login",
+			want:   Unauthenticated,
+			is:     ErrUnauthenticated,
+		},
+		{
+			name:   "05. it should return Conflict for non-empty folders.",
+			op:     func() error { return client.DeleteFolder("luke", "/Work", false) },
+			wantOp: "delete_folder",
+			want:   Conflict,
+			is:     ErrConflict,
+		},
+		{
+			name:   "06. it should return Invalid for invalid arguments.",
+			op:     func() error { return client.Share("luke", "/Work", "mark", "rwx") },
+			wantOp: "share_folder",
+			want:   Invalid,
+			is:     ErrInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("error = %v, want *Error", err)
+			}
+			if e.Op != tt.wantOp {
+				t.Errorf("Error.Op = %q, want %q", e.Op, tt.wantOp)
+			}
+			if KindOf(err) != tt.want {
+				t.Errorf("KindOf() = %v, want %v", KindOf(err), tt.want)
+			}
+			if !errors.Is(err, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestClient_Context(t *testing.T) {
+	client, _ := New()
+	client.Register("luke", "")
+	client.CreateFolder("luke", "Work", "")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		op     func() error
+		wantOp string
+	}{
+		{
+			name: "01. it should not create the folder once the context is done.",
+			op: func() error {
+				_, err := client.CreateFolderContext(canceled, "luke", "Home", "")
+				return err
+			},
+			wantOp: "create_folder",
+		},
+		{
+			name:   "02. it should not delete the folder once the context is done.",
+			op:     func() error { return client.DeleteFolderContext(canceled, "luke", "/Work", true) },
+			wantOp: "delete_folder",
+		},
+		{
+			name: "03. it should not upload the file once the context is done.",
+			op: func() error {
+				_, err := client.UploadContext(canceled, "luke", "/Work", "1.tc", "", strings.NewReader("hello"))
+				return err
+			},
+			wantOp: "upload_file",
+		},
+		{
+			name: "04. it should not create the group once the context is done.",
+			op: func() error {
+				_, err := client.CreateGroupContext(canceled, "luke", "team")
+				return err
+			},
+			wantOp: "create_group",
+		},
+		{
+			name:   "05. it should not empty the trash once the context is done.",
+			op:     func() error { return client.EmptyTrashContext(canceled, "luke") },
+			wantOp: "empty_trash",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("error = %v, want %v", err, context.Canceled)
+			}
+			if e, ok := err.(*Error); !ok || e.Op != tt.wantOp {
+				t.Errorf("error = %#v, want *Error of %q", err, tt.wantOp)
+			}
+		})
+	}
+
+	folders, _ := client.Folders("luke", "", "")
+	if len(folders) != 1 {
+		t.Errorf("Client.Folders() = %d folders, want 1", len(folders))
+	}
+}